@@ -5,6 +5,7 @@ import (
 	"file-url-parser/router"
 	"log"
 	"os"
+	_ "time/tzdata" // 内嵌时区数据，运行镜像（alpine）中没有时区数据库时timezone参数仍然可用
 
 	"github.com/gin-gonic/gin"
 )
@@ -1,27 +1,36 @@
 package config
 
 import (
+	"file-url-parser/model"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Config 应用配置
 type Config struct {
 	Port             string
 	PythonServiceURL string
-	MaxFileSize      int64
+	MaxFileSize      int64 // 默认最大文件大小（字节），可被请求参数覆盖
+	MaxFileSizeLimit int64 // 请求参数可以指定的最大文件大小上限（字节）
 	AllowedFormats   []string
-	MaxAllowedRows   int // 添加最大允许行数配置
-	UseHeaderAsKey   bool // 是否使用表头作为键
-	RateLimit        int  // 接口调用频率限制（次/秒）
+	MaxAllowedRows   int                  // 默认最大允许行数，可被请求参数覆盖
+	UseHeaderAsKey   bool                 // 默认是否使用表头作为键，可被请求参数覆盖
+	RateLimit        int                  // 接口调用频率限制（次/秒）
+	OutboundPolicy   model.OutboundPolicy // 下载文件时的出站URL策略
+	Download         model.DownloadConfig // 下载文件的超时、重定向和重试设置
 }
 
-var appConfig *Config
+var (
+	appConfig  *Config
+	configOnce sync.Once
+)
 
-// InitConfig 初始化配置
+// InitConfig 初始化配置（只加载一次，并发安全）
 func InitConfig() *Config {
-	if appConfig == nil {
+	configOnce.Do(func() {
 		port := os.Getenv("PORT")
 		if port == "" {
 			port = "4001"
@@ -40,6 +49,12 @@ func InitConfig() *Config {
 			}
 		}
 
+		// 从环境变量读取请求可以指定的最大文件大小上限，默认与最大文件大小相同
+		maxFileSizeLimit := maxFileSize
+		if size, err := strconv.ParseInt(os.Getenv("MAX_FILE_SIZE_LIMIT"), 10, 64); err == nil && size > maxFileSize {
+			maxFileSizeLimit = size
+		}
+
 		// 从环境变量读取最大允许行数
 		maxAllowedRowsStr := os.Getenv("MAX_ALLOWED_ROWS")
 		maxAllowedRows := 200 // 默认200行
@@ -65,94 +80,133 @@ func InitConfig() *Config {
 			}
 		}
 
+		// 从环境变量读取出站URL策略
+		outboundPolicy := model.OutboundPolicy{
+			AllowedSchemes:  []string{"http", "https"},
+			AllowedHosts:    splitEnvList(os.Getenv("OUTBOUND_ALLOWED_HOSTS")),
+			DeniedHosts:     splitEnvList(os.Getenv("OUTBOUND_DENIED_HOSTS")),
+			AllowPrivateIPs: strings.ToLower(os.Getenv("OUTBOUND_ALLOW_PRIVATE_IPS")) == "true",
+		}
+		if schemes := splitEnvList(os.Getenv("OUTBOUND_ALLOWED_SCHEMES")); len(schemes) > 0 {
+			outboundPolicy.AllowedSchemes = schemes
+		}
+		for _, portStr := range splitEnvList(os.Getenv("OUTBOUND_ALLOWED_PORTS")) {
+			if port, err := strconv.Atoi(portStr); err == nil && port > 0 && port <= 65535 {
+				outboundPolicy.AllowedPorts = append(outboundPolicy.AllowedPorts, port)
+			}
+		}
+
+		// 从环境变量读取下载文件的超时、重定向和重试设置
+		download := model.DownloadConfig{
+			ConnectTimeout: envDuration("DOWNLOAD_CONNECT_TIMEOUT", 10*time.Second),
+			HeaderTimeout:  envDuration("DOWNLOAD_HEADER_TIMEOUT", 30*time.Second),
+			Timeout:        envDuration("DOWNLOAD_TIMEOUT", 2*time.Minute),
+			MaxRedirects:   envInt("DOWNLOAD_MAX_REDIRECTS", 10),
+			MaxRetries:     envInt("DOWNLOAD_MAX_RETRIES", 2),
+			RetryDelay:     envDuration("DOWNLOAD_RETRY_DELAY", 500*time.Millisecond),
+			RetryMaxDelay:  envDuration("DOWNLOAD_RETRY_MAX_DELAY", 10*time.Second),
+		}
+
 		appConfig = &Config{
 			Port:             port,
 			PythonServiceURL: pythonServiceURL,
 			MaxFileSize:      maxFileSize,
+			MaxFileSizeLimit: maxFileSizeLimit,
 			MaxAllowedRows:   maxAllowedRows,
 			UseHeaderAsKey:   useHeaderAsKey,
 			RateLimit:        rateLimit,
+			OutboundPolicy:   outboundPolicy,
+			Download:         download,
 			AllowedFormats: []string{
 				".xlsx", ".xls", // Excel
-				".csv",          // CSV
+				".csv", ".tsv", ".psv", // CSV（逗号、制表符、竖线分隔）
 				".docx", ".doc", // Word
 				".pdf", // PDF
 				".txt", // Text
 				".md",  // Markdown
 			},
 		}
-	}
+	})
 	return appConfig
 }
 
+// splitEnvList 将逗号分隔的环境变量值拆分为小写的列表，去掉空项
+func splitEnvList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// envInt 读取非负整数环境变量，未设置或无效时返回默认值
+func envInt(name string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value >= 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// envDuration 读取时长环境变量（如 "30s"、"2m"，纯数字按秒计算），未设置或无效时返回默认值
+func envDuration(name string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if duration, err := time.ParseDuration(value); err == nil && duration > 0 {
+		return duration
+	}
+	return defaultValue
+}
+
 // GetPort 获取端口号
 func GetPort() string {
-	if appConfig == nil {
-		InitConfig()
-	}
-	return appConfig.Port
+	return InitConfig().Port
 }
 
 // GetPythonServiceURL 获取Python服务URL
 func GetPythonServiceURL() string {
-	if appConfig == nil {
-		InitConfig()
-	}
-	return appConfig.PythonServiceURL
+	return InitConfig().PythonServiceURL
 }
 
 // GetMaxFileSize 获取最大文件大小
 func GetMaxFileSize() int64 {
-	if appConfig == nil {
-		InitConfig()
-	}
-	return appConfig.MaxFileSize
+	return InitConfig().MaxFileSize
+}
+
+// GetMaxFileSizeLimit 获取请求可以指定的最大文件大小上限
+func GetMaxFileSizeLimit() int64 {
+	return InitConfig().MaxFileSizeLimit
 }
 
 // GetAllowedFormats 获取允许的文件格式
 func GetAllowedFormats() []string {
-	if appConfig == nil {
-		InitConfig()
-	}
-	return appConfig.AllowedFormats
+	return InitConfig().AllowedFormats
 }
 
 // GetMaxAllowedRows 获取最大允许行数
 func GetMaxAllowedRows() int {
-	if appConfig == nil {
-		InitConfig()
-	}
-	return appConfig.MaxAllowedRows
-}
-
-// SetMaxAllowedRows 设置最大允许行数（临时覆盖）
-func SetMaxAllowedRows(value int) {
-	if appConfig == nil {
-		InitConfig()
-	}
-	appConfig.MaxAllowedRows = value
+	return InitConfig().MaxAllowedRows
 }
 
 // GetUseHeaderAsKey 获取是否使用表头作为键
 func GetUseHeaderAsKey() bool {
-	if appConfig == nil {
-		InitConfig()
-	}
-	return appConfig.UseHeaderAsKey
-}
-
-// SetUseHeaderAsKey 设置是否使用表头作为键（临时覆盖）
-func SetUseHeaderAsKey(value bool) {
-	if appConfig == nil {
-		InitConfig()
-	}
-	appConfig.UseHeaderAsKey = value
+	return InitConfig().UseHeaderAsKey
 }
 
 // GetRateLimit 获取接口调用频率限制
 func GetRateLimit() int {
-	if appConfig == nil {
-		InitConfig()
-	}
-	return appConfig.RateLimit
+	return InitConfig().RateLimit
+}
+
+// GetOutboundPolicy 获取下载文件时的出站URL策略
+func GetOutboundPolicy() model.OutboundPolicy {
+	return InitConfig().OutboundPolicy
+}
+
+// GetDownloadConfig 获取下载文件的超时、重定向和重试设置
+func GetDownloadConfig() model.DownloadConfig {
+	return InitConfig().Download
 }
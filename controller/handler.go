@@ -1,10 +1,15 @@
 package controller
 
 import (
+	"errors"
 	"file-url-parser/config"
 	"file-url-parser/model"
 	"file-url-parser/service"
+	"file-url-parser/utils"
 	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 )
@@ -29,56 +34,370 @@ func ParseURLHandler(c *gin.Context) {
 		return
 	}
 
+	// 合并请求参数与默认配置，得到本次请求的解析选项
+	options, err := buildParseOptions(&request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	// 解析URL内容
+	result, err := service.ParseURLContent(c.Request.Context(), request.URL, options)
+	if code := utils.PolicyErrorCode(err); code != "" {
+		c.JSON(http.StatusForbidden, model.ErrorResponse{
+			Error: "URL不符合访问策略: " + err.Error(),
+			Code:  code,
+		})
+		return
+	}
+	var tooLarge *utils.FileTooLargeError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, model.ErrorResponse{
+			Error:       err.Error(),
+			Code:        utils.FileTooLargeCode,
+			MaxFileSize: tooLarge.Limit,
+			FileSize:    tooLarge.Size,
+		})
+		return
+	}
+	if code := service.FileTypeErrorCode(err); code != "" {
+		// HTML页面和内容不符的文件无法解析，加密文件是不支持的格式
+		status := http.StatusUnprocessableEntity
+		if code == service.FileTypeCodeEncrypted {
+			status = http.StatusUnsupportedMediaType
+		}
+		c.JSON(status, model.ErrorResponse{
+			Error: err.Error(),
+			Code:  code,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: "解析失败: " + err.Error(),
+		})
+		return
+	}
+
+	// 返回结果
+	c.JSON(http.StatusOK, result)
+}
+
+// buildParseOptions 根据请求参数构建解析选项，未指定的参数使用全局配置的默认值
+func buildParseOptions(request *model.URLRequest) (model.ParseOptions, error) {
+	options := model.ParseOptions{
+		UseHeaderAsKey:  config.GetUseHeaderAsKey(),
+		MaxAllowedRows:  config.GetMaxAllowedRows(),
+		MaxFileSize:     config.GetMaxFileSize(),
+		Offset:          0,
+		Limit:           -1, // 默认不限制
+		NumericMode:     model.NumericModeAuto,
+		CellValues:      model.CellValuesTyped,
+		MergedCells:     model.MergedCellsNone,
+		HeaderSeparator: "/",
+		HeaderCase:      model.HeaderCaseOriginal,
+		Tables:          model.TablesFirst,
+		Formulas:        model.FormulasCached,
+		CellMetadata:    model.CellMetadataNone,
+		IncludeHidden: model.HiddenOptions{
+			Rows:    true,
+			Columns: true,
+			Sheets:  true,
+		},
+		List: model.ListOptions{
+			Enabled:    true,
+			Separators: []string{","},
+		},
+	}
+
 	// 设置是否使用表头作为键
-	var useHeaderAsKey bool
 	if request.UseHeaderAsKey != nil {
-		// 如果请求中指定了，则使用请求中的值
-		useHeaderAsKey = *request.UseHeaderAsKey
-		// 临时覆盖全局配置
-		config.SetUseHeaderAsKey(useHeaderAsKey)
+		options.UseHeaderAsKey = *request.UseHeaderAsKey
 	}
 
 	// 设置最大行数限制
 	if request.MaxRows != nil {
-		// 如果请求中指定了，则使用请求中的值
-		maxRows := *request.MaxRows
 		// 验证最大行数是否有效
-		if maxRows < -1 {
-			c.JSON(http.StatusBadRequest, model.ErrorResponse{
-				Error: "最大行数必须大于等于-1，-1表示无限制",
-			})
-			return
+		if *request.MaxRows < -1 {
+			return options, errors.New("最大行数必须大于等于-1，-1表示无限制")
+		}
+		options.MaxAllowedRows = *request.MaxRows
+	}
+
+	// 设置最大文件大小，不能超过配置的上限
+	if request.MaxFileSize != nil {
+		if *request.MaxFileSize <= 0 {
+			return options, errors.New("最大文件大小必须大于0")
+		}
+		if limit := config.GetMaxFileSizeLimit(); *request.MaxFileSize > limit {
+			return options, errors.New("最大文件大小不能超过" + strconv.FormatInt(limit, 10) + "字节")
+		}
+		options.MaxFileSize = *request.MaxFileSize
+	}
+
+	// 设置指定的文件类型
+	if request.FileType != "" {
+		fileType, err := parseFileType(request.FileType)
+		if err != nil {
+			return options, err
 		}
-		// 临时覆盖全局配置
-		config.SetMaxAllowedRows(maxRows)
+		options.FileType = fileType
 	}
 
 	// 设置偏移量和每页数据量
-	offset := 0
-	if request.Offset != nil {
-		offset = *request.Offset
-		if offset < 0 {
-			offset = 0
+	if request.Offset != nil && *request.Offset > 0 {
+		options.Offset = *request.Offset
+	}
+
+	if request.Limit != nil && *request.Limit >= 0 {
+		options.Limit = *request.Limit
+	}
+
+	// 设置工作表选择
+	switch sheet := request.Sheet.(type) {
+	case nil:
+		// 未指定时使用第一个工作表
+	case string:
+		options.SheetName = sheet
+	case float64:
+		if sheet < 0 || sheet != float64(int(sheet)) {
+			return options, errors.New("工作表索引必须是大于等于0的整数")
 		}
+		options.SheetIndex = int(sheet)
+	default:
+		return options, errors.New("sheet参数必须是工作表名称或索引")
 	}
+	options.AllSheets = request.AllSheets
+	options.ListSheets = request.ListSheets
 
-	limit := -1 // 默认不限制
-	if request.Limit != nil {
-		limit = *request.Limit
-		if limit < 0 {
-			limit = -1 // 无限制
+	// 设置CSV格式参数
+	var err error
+	if options.CSV.Delimiter, err = parseDialectChar(request.Delimiter, "delimiter"); err != nil {
+		return options, err
+	}
+	if options.CSV.Quote, err = parseDialectChar(request.Quote, "quote"); err != nil {
+		return options, err
+	}
+	if options.CSV.Comment, err = parseDialectChar(request.Comment, "comment"); err != nil {
+		return options, err
+	}
+	if options.CSV.Delimiter != 0 && options.CSV.Delimiter == options.CSV.Quote {
+		return options, errors.New("delimiter和quote不能是同一个字符")
+	}
+	options.CSV.LazyQuotes = request.LazyQuotes
+
+	// 设置字符编码
+	if request.Encoding != "" {
+		if options.Encoding, err = service.NormalizeEncodingName(request.Encoding); err != nil {
+			return options, err
 		}
 	}
 
-	// 解析URL内容
-	result, err := service.ParseURLContent(request.URL, offset, limit)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Error: "解析失败: " + err.Error(),
-		})
-		return
+	// 设置数值转换模式
+	switch request.NumericMode {
+	case "":
+	case model.NumericModeAuto, model.NumericModeString, model.NumericModeFloat, model.NumericModeDecimal:
+		options.NumericMode = request.NumericMode
+	default:
+		return options, errors.New("numeric_mode参数必须是auto、string、float或decimal")
 	}
 
-	// 返回结果
-	c.JSON(http.StatusOK, result)
+	// 设置单元格值输出方式
+	switch request.CellValues {
+	case "":
+	case model.CellValuesTyped, model.CellValuesFormatted, model.CellValuesBoth:
+		options.CellValues = request.CellValues
+	default:
+		return options, errors.New("cell_values参数必须是typed、formatted或both")
+	}
+
+	// 设置合并单元格填充方式
+	switch request.MergedCells {
+	case "":
+	case model.MergedCellsNone, model.MergedCellsFillDown, model.MergedCellsFillAcross, model.MergedCellsFillBoth:
+		options.MergedCells = request.MergedCells
+	default:
+		return options, errors.New("merged_cells参数必须是none、fill_down、fill_across或fill_both")
+	}
+
+	// 设置公式单元格的输出方式
+	switch request.Formulas {
+	case "":
+	case model.FormulasCached, model.FormulasText, model.FormulasBoth, model.FormulasCalculate:
+		options.Formulas = request.Formulas
+	default:
+		return options, errors.New("formulas参数必须是cached、text、both或calculate")
+	}
+
+	// 设置单元格附加信息的输出方式
+	switch request.CellMetadata {
+	case "":
+	case model.CellMetadataNone, model.CellMetadataBasic, model.CellMetadataStyle:
+		options.CellMetadata = request.CellMetadata
+	default:
+		return options, errors.New("cell_metadata参数必须是none、basic或style")
+	}
+
+	// 设置是否包括隐藏的行、列和工作表
+	if request.IncludeHiddenRows != nil {
+		options.IncludeHidden.Rows = *request.IncludeHiddenRows
+	}
+	if request.IncludeHiddenColumns != nil {
+		options.IncludeHidden.Columns = *request.IncludeHiddenColumns
+	}
+	if request.IncludeHiddenSheets != nil {
+		options.IncludeHidden.Sheets = *request.IncludeHiddenSheets
+	}
+
+	// 设置表头行数和多行表头的分隔符
+	if request.HeaderRows != nil {
+		if *request.HeaderRows < 0 {
+			return options, errors.New("header_rows必须大于等于0，0表示自动检测")
+		}
+		options.HeaderRows = *request.HeaderRows
+	}
+	if request.HeaderSeparator != nil {
+		options.HeaderSeparator = *request.HeaderSeparator
+	}
+
+	// 设置表头键名的命名风格
+	switch request.HeaderCase {
+	case "":
+	case model.HeaderCaseOriginal, model.HeaderCaseSnake, model.HeaderCaseCamel:
+		options.HeaderCase = request.HeaderCase
+	default:
+		return options, errors.New("header_case参数必须是original、snake或camel")
+	}
+
+	// 设置表格范围
+	if request.Range != "" {
+		if request.HeaderRow != nil || request.StartCol != nil {
+			return options, errors.New("range参数不能与header_row、start_col同时使用")
+		}
+		if options.Table, err = service.ParseCellRange(request.Range); err != nil {
+			return options, err
+		}
+	}
+	if request.HeaderRow != nil {
+		if *request.HeaderRow < 1 {
+			return options, errors.New("header_row必须是从1开始的行号")
+		}
+		options.Table.HeaderRow = *request.HeaderRow
+	}
+	switch col := request.StartCol.(type) {
+	case nil:
+	case string:
+		if options.Table.StartCol, err = service.ParseColumnName(col); err != nil {
+			return options, err
+		}
+	case float64:
+		if col < 1 || col != float64(int(col)) {
+			return options, errors.New("start_col参数必须是列名（如 B）或从1开始的列号")
+		}
+		options.Table.StartCol = int(col)
+	default:
+		return options, errors.New("start_col参数必须是列名（如 B）或从1开始的列号")
+	}
+
+	// 设置表格提取方式
+	switch request.Tables {
+	case "":
+	case model.TablesFirst, model.TablesAll:
+		options.Tables = request.Tables
+	default:
+		return options, errors.New("tables参数必须是first或all")
+	}
+	if options.Tables == model.TablesAll && options.Table != (model.TableRange{}) {
+		return options, errors.New("tables=all不能与range、header_row、start_col同时使用")
+	}
+
+	// 设置按Excel表格或名称解析
+	options.TableName = request.TableName
+	options.NamedRange = request.NamedRange
+	options.ListTables = request.ListTables
+	if options.TableName != "" || options.NamedRange != "" {
+		switch {
+		case options.TableName != "" && options.NamedRange != "":
+			return options, errors.New("table_name和named_range不能同时使用")
+		case options.Table != (model.TableRange{}) || options.Tables == model.TablesAll:
+			return options, errors.New("table_name、named_range不能与range、header_row、start_col、tables=all同时使用")
+		case options.AllSheets:
+			return options, errors.New("table_name、named_range不能与all_sheets同时使用")
+		}
+	}
+
+	// 设置日期识别与输出参数
+	if options.Date.Order, err = service.ParseDateOrder(request.DateOrder); err != nil {
+		return options, err
+	}
+	if request.DateLayout != "" {
+		if options.Date.Layout, err = service.ParseDateLayout(request.DateLayout); err != nil {
+			return options, err
+		}
+	}
+	if request.Timezone != "" {
+		if options.Date.Location, err = service.ParseTimezone(request.Timezone); err != nil {
+			return options, err
+		}
+	}
+
+	// 设置列表拆分参数
+	if request.SplitLists != nil {
+		options.List.Enabled = *request.SplitLists
+	}
+	options.List.Columns = request.ListColumns
+	if len(request.ListSeparators) > 0 {
+		options.List.Separators = make([]string, 0, len(request.ListSeparators))
+		for _, value := range request.ListSeparators {
+			separator, err := parseListSeparator(value)
+			if err != nil {
+				return options, err
+			}
+			options.List.Separators = append(options.List.Separators, separator)
+		}
+	}
+	options.List.DropEmpty = request.DropEmptyItems
+
+	return options, nil
+}
+
+// parseListSeparator 解析列表分隔符，"newline"和"\n"表示换行
+func parseListSeparator(value string) (string, error) {
+	switch value {
+	case ",", "，", ";", "|":
+		return value, nil
+	case "\n", `\n`, "newline":
+		return "\n", nil
+	}
+	return "", errors.New("list_separators参数只能包含 \",\"、\"，\"、\";\"、\"|\" 或 \"\\n\"")
+}
+
+// parseFileType 解析指定的文件类型，支持带或不带点号的扩展名（如 "xlsx"、".csv"），必须是支持的文件格式
+func parseFileType(value string) (string, error) {
+	fileType := "." + strings.TrimPrefix(strings.ToLower(strings.TrimSpace(value)), ".")
+	allowedFormats := config.GetAllowedFormats()
+	for _, allowed := range allowedFormats {
+		if fileType == allowed {
+			return fileType, nil
+		}
+	}
+	return "", errors.New("file_type参数必须是支持的文件类型: " + strings.Join(allowedFormats, "、"))
+}
+
+// parseDialectChar 解析CSV格式参数中的单个字符，空字符串表示自动检测
+func parseDialectChar(value string, name string) (rune, error) {
+	switch value {
+	case "":
+		return 0, nil
+	case "tab", `\t`:
+		return '\t', nil
+	}
+
+	runes := []rune(value)
+	if len(runes) != 1 || runes[0] == '\r' || runes[0] == '\n' || runes[0] == utf8.RuneError {
+		return 0, errors.New(name + "参数必须是单个字符")
+	}
+	return runes[0], nil
 }
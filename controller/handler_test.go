@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+// TestMain 允许下载测试服务器（127.0.0.1）上的文件，需要在第一次读取配置之前设置
+func TestMain(m *testing.M) {
+	os.Setenv("OUTBOUND_ALLOW_PRIVATE_IPS", "true")
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// requestCase 一个解析请求的参数及其预期结果
+type requestCase struct {
+	name     string
+	params   map[string]interface{} // 除url之外的请求参数
+	status   int
+	wantKeys []string      // 每行数据的键
+	wantRows []interface{} // 每行第二列的值
+}
+
+var requestCases = []requestCase{
+	{
+		name:     "使用表头作为键",
+		params:   map[string]interface{}{"use_header_as_key": true},
+		status:   http.StatusOK,
+		wantKeys: []string{"name", "qty"},
+		wantRows: []interface{}{json.Number("1"), json.Number("2"), json.Number("3"), json.Number("4"), json.Number("5"), json.Number("6")},
+	},
+	{
+		name:     "不使用表头作为键",
+		params:   map[string]interface{}{"use_header_as_key": false},
+		status:   http.StatusOK,
+		wantKeys: []string{"Col_1", "Col_2"},
+		wantRows: []interface{}{json.Number("1"), json.Number("2"), json.Number("3"), json.Number("4"), json.Number("5"), json.Number("6")},
+	},
+	{
+		name:     "分页",
+		params:   map[string]interface{}{"offset": 2, "limit": 2},
+		status:   http.StatusOK,
+		wantKeys: []string{"name", "qty"},
+		wantRows: []interface{}{json.Number("3"), json.Number("4")},
+	},
+	{
+		name:   "超过最大行数",
+		params: map[string]interface{}{"max_rows": 3},
+		status: http.StatusInternalServerError,
+	},
+	{
+		name:     "不转换数值",
+		params:   map[string]interface{}{"use_header_as_key": false, "numeric_mode": "string"},
+		status:   http.StatusOK,
+		wantKeys: []string{"Col_1", "Col_2"},
+		wantRows: []interface{}{"1", "2", "3", "4", "5", "6"},
+	},
+}
+
+// writeTestFiles 在目录中创建内容相同的CSV和.xlsx测试文件
+func writeTestFiles(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "data.csv"), []byte("name,qty\na,1\nb,2\nc,3\nd,4\ne,5\nf,6\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	rows := [][]interface{}{{"name", "qty"}, {"a", 1}, {"b", 2}, {"c", 3}, {"d", 4}, {"e", 5}, {"f", 6}}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow("Sheet1", cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.SaveAs(filepath.Join(dir, "data.xlsx")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkResponse 检查解析接口的响应是否符合预期
+func checkResponse(tc requestCase, recorder *httptest.ResponseRecorder) error {
+	if recorder.Code != tc.status {
+		return fmt.Errorf("%s: 状态码为 %d，应为 %d: %s", tc.name, recorder.Code, tc.status, recorder.Body)
+	}
+	if tc.status != http.StatusOK {
+		return nil
+	}
+
+	var response struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	decoder := json.NewDecoder(recorder.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&response); err != nil {
+		return fmt.Errorf("%s: %v", tc.name, err)
+	}
+	if len(response.Data) != len(tc.wantRows) {
+		return fmt.Errorf("%s: 数据行数为 %d，应为 %d", tc.name, len(response.Data), len(tc.wantRows))
+	}
+	for i, row := range response.Data {
+		if len(row) != len(tc.wantKeys) {
+			return fmt.Errorf("%s: 第%d行的键为 %v，应为 %v", tc.name, i+1, row, tc.wantKeys)
+		}
+		for _, key := range tc.wantKeys {
+			if _, ok := row[key]; !ok {
+				return fmt.Errorf("%s: 第%d行缺少键 %s: %v", tc.name, i+1, key, row)
+			}
+		}
+		if value := row[tc.wantKeys[1]]; !reflect.DeepEqual(value, tc.wantRows[i]) {
+			return fmt.Errorf("%s: 第%d行的值为 %#v，应为 %#v", tc.name, i+1, value, tc.wantRows[i])
+		}
+	}
+	return nil
+}
+
+// TestConcurrentParseRequests 参数相互冲突的请求（如use_header_as_key为true和false）并发调用解析接口时互不影响（使用 go test -race 运行）
+func TestConcurrentParseRequests(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir)
+	fileServer := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer fileServer.Close()
+
+	router := gin.New()
+	router.POST("/parse", ParseURLHandler)
+
+	const rounds = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, rounds*len(requestCases)*2)
+	for round := 0; round < rounds; round++ {
+		for _, tc := range requestCases {
+			for _, fileName := range []string{"data.csv", "data.xlsx"} {
+				params := map[string]interface{}{"url": fileServer.URL + "/" + fileName}
+				for key, value := range tc.params {
+					params[key] = value
+				}
+				body, err := json.Marshal(params)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					recorder := httptest.NewRecorder()
+					request := httptest.NewRequest(http.MethodPost, "/parse", bytes.NewReader(body))
+					request.Header.Set("Content-Type", "application/json")
+					router.ServeHTTP(recorder, request)
+					if err := checkResponse(tc, recorder); err != nil {
+						errs <- fmt.Errorf("%s %w", fileName, err)
+					}
+				}()
+			}
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -6,15 +6,256 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // URLRequest 请求结构
 type URLRequest struct {
-	URL           string `json:"url" binding:"required"`
-	UseHeaderAsKey *bool  `json:"use_header_as_key,omitempty"` // 是否使用表头作为键，null表示使用默认配置
-	MaxRows       *int   `json:"max_rows,omitempty"`          // 最大行数限制，null表示使用默认配置，-1表示无限制
-	Offset        *int   `json:"offset,omitempty"`            // 数据偏移量，从0开始，表示从第几行开始获取数据（不包括表头）
-	Limit         *int   `json:"limit,omitempty"`             // 每次获取的数据行数，不传或为null表示不限制
+	URL                  string      `json:"url" binding:"required"`
+	UseHeaderAsKey       *bool       `json:"use_header_as_key,omitempty"`      // 是否使用表头作为键，null表示使用默认配置
+	MaxRows              *int        `json:"max_rows,omitempty"`               // 最大行数限制，null表示使用默认配置，-1表示无限制
+	MaxFileSize          *int64      `json:"max_file_size,omitempty"`          // 最大文件大小（字节），null表示使用默认配置，不能超过配置的上限
+	FileType             string      `json:"file_type,omitempty"`              // 指定文件类型（如 "xlsx"、".csv"），不指定时根据文件内容、Content-Type和扩展名判断
+	Offset               *int        `json:"offset,omitempty"`                 // 数据偏移量，从0开始，表示从第几行开始获取数据（不包括表头）
+	Limit                *int        `json:"limit,omitempty"`                  // 每次获取的数据行数，不传或为null表示不限制
+	Sheet                interface{} `json:"sheet,omitempty"`                  // Excel工作表，可传工作表名称（字符串）或索引（数字，从0开始），默认第一个工作表
+	AllSheets            bool        `json:"all_sheets,omitempty"`             // 是否解析所有工作表，结果按工作表名称分组返回
+	ListSheets           bool        `json:"list_sheets,omitempty"`            // 只列出工作表信息（名称、可见性、数据范围），不解析数据
+	Delimiter            string      `json:"delimiter,omitempty"`              // CSV分隔符（单个字符，"tab"或"\\t"表示制表符），默认自动检测
+	Quote                string      `json:"quote,omitempty"`                  // CSV引号字符，默认自动检测
+	Comment              string      `json:"comment,omitempty"`                // CSV注释行的起始字符，默认没有注释行
+	LazyQuotes           *bool       `json:"lazy_quotes,omitempty"`            // 是否允许不规范的引号，null表示自动检测
+	Encoding             string      `json:"encoding,omitempty"`               // CSV/文本文件的字符编码（如 GBK、UTF-16LE），默认自动检测
+	NumericMode          string      `json:"numeric_mode,omitempty"`           // 数值转换模式：auto、string、float、decimal，默认auto
+	SplitLists           *bool       `json:"split_lists,omitempty"`            // 是否将分隔符分隔的内容拆分为数组，null表示使用默认值（拆分）
+	ListColumns          []string    `json:"list_columns,omitempty"`           // 只拆分这些列（表头名称或Col_N键名），为空时拆分所有列
+	ListSeparators       []string    `json:"list_separators,omitempty"`        // 列表分隔符，可选 ","、"，"、";"、"|"、"\n"，默认 [","]
+	DropEmptyItems       bool        `json:"drop_empty_items,omitempty"`       // 是否去掉拆分后的空项目
+	CellValues           string      `json:"cell_values,omitempty"`            // 单元格值输出方式：typed、formatted、both，默认typed
+	DateOrder            string      `json:"date_order,omitempty"`             // 年份在末尾的纯数字日期的顺序：YMD、DMY、MDY，默认自动判断
+	DateLayout           string      `json:"date_layout,omitempty"`            // 日期输出格式：iso、date、datetime、rfc3339或Go时间格式，默认iso
+	Timezone             string      `json:"timezone,omitempty"`               // 时区：IANA名称（如 Asia/Shanghai）或UTC偏移（如 +08:00）
+	MergedCells          string      `json:"merged_cells,omitempty"`           // Excel合并单元格的填充方式：none、fill_down、fill_across、fill_both，默认none
+	HeaderRows           *int        `json:"header_rows,omitempty"`            // 表头行数，null或0表示自动检测多行表头
+	HeaderSeparator      *string     `json:"header_separator,omitempty"`       // 多行表头组合键名时使用的分隔符，默认"/"
+	HeaderCase           string      `json:"header_case,omitempty"`            // 表头键名的命名风格：original、snake、camel，默认original
+	Range                string      `json:"range,omitempty"`                  // 表格范围（A1格式，如 B4:K200），第一行为表头，指定后不再自动检测
+	HeaderRow            *int        `json:"header_row,omitempty"`             // 表头所在的行号（从1开始），指定后不再自动检测表头行
+	StartCol             interface{} `json:"start_col,omitempty"`              // 表格的起始列，可传列名（如 "B"）或列号（从1开始）
+	Tables               string      `json:"tables,omitempty"`                 // 表格提取方式：first（默认，只解析第一个表格）、all（解析工作表中的所有表格）
+	TableName            string      `json:"table_name,omitempty"`             // 按Excel表格（插入 > 表格）的名称解析该表格的区域
+	NamedRange           string      `json:"named_range,omitempty"`            // 按Excel名称（如 SalesData）解析其引用的区域
+	ListTables           bool        `json:"list_tables,omitempty"`            // 只列出工作簿中定义的表格和名称，不解析数据
+	Formulas             string      `json:"formulas,omitempty"`               // Excel公式单元格的输出方式：cached、text、both、calculate，默认cached
+	CellMetadata         string      `json:"cell_metadata,omitempty"`          // Excel单元格附加信息的输出方式：none、basic（超链接和批注）、style（同时输出字体和填充），默认none
+	IncludeHiddenRows    *bool       `json:"include_hidden_rows,omitempty"`    // 是否包括Excel中的隐藏行（含筛选隐藏的行），null表示包括
+	IncludeHiddenColumns *bool       `json:"include_hidden_columns,omitempty"` // 是否包括Excel中的隐藏列，null表示包括
+	IncludeHiddenSheets  *bool       `json:"include_hidden_sheets,omitempty"`  // 是否包括Excel中的隐藏工作表，null表示包括
+}
+
+// ParseOptions 单次请求的解析选项
+// 由请求参数与全局配置合并得到，只在本次请求内生效，不会修改全局配置
+type ParseOptions struct {
+	UseHeaderAsKey bool   // 是否使用表头作为键
+	MaxAllowedRows int    // 最大允许行数，-1表示无限制
+	Offset         int    // 数据偏移量（不包括表头）
+	Limit          int    // 返回的数据行数，-1表示不限制
+	MaxFileSize    int64  // 最大文件大小（字节）
+	FileType       string // 请求中指定的文件类型（如 ".xlsx"），为空时自动判断
+
+	SheetName  string // 指定的工作表名称，优先于SheetIndex
+	SheetIndex int    // 指定的工作表索引（从0开始）
+	AllSheets  bool   // 是否解析所有工作表
+	ListSheets bool   // 是否只列出工作表信息
+
+	CSV      CSVOptions // CSV格式参数
+	Encoding string     // CSV/文本文件的字符编码，空字符串表示自动检测
+
+	NumericMode string      // 数值转换模式，见 NumericMode* 常量
+	List        ListOptions // 列表拆分参数
+	CellValues  string      // 单元格值输出方式，见 CellValues* 常量
+	Date        DateOptions // 日期识别与输出参数
+
+	MergedCells string // Excel合并单元格的填充方式，见 MergedCells* 常量
+
+	HeaderRows      int    // 表头行数，0表示自动检测
+	HeaderSeparator string // 多行表头组合键名时使用的分隔符
+	HeaderCase      string // 表头键名的命名风格，见 HeaderCase* 常量
+
+	Table  TableRange // 指定的表格范围，零值表示自动检测
+	Tables string     // 表格提取方式，见 Tables* 常量
+
+	TableName  string // Excel表格名称
+	NamedRange string // Excel名称
+	ListTables bool   // 是否只列出定义的表格和名称
+
+	Formulas     string // Excel公式单元格的输出方式，见 Formulas* 常量
+	CellMetadata string // Excel单元格附加信息的输出方式，见 CellMetadata* 常量
+
+	IncludeHidden HiddenOptions // 是否包括Excel中的隐藏行、列和工作表
+}
+
+// HiddenOptions 是否包括Excel中隐藏的行、列和工作表，默认都包括
+type HiddenOptions struct {
+	Rows    bool // 是否包括隐藏行
+	Columns bool // 是否包括隐藏列
+	Sheets  bool // 是否包括隐藏工作表
+}
+
+// Excel单元格附加信息的输出方式
+const (
+	CellMetadataNone  = "none"  // 不输出
+	CellMetadataBasic = "basic" // 输出超链接和批注
+	CellMetadataStyle = "style" // 输出超链接、批注以及字体和填充样式
+)
+
+// CellMetadata 单元格的附加信息，按cell_metadata参数输出到与data对应的metadata中
+type CellMetadata struct {
+	Cell      string       `json:"cell"`                // 单元格位置，如 "B5"
+	Hyperlink string       `json:"hyperlink,omitempty"` // 超链接地址，链接到工作簿内部时为位置（如 "Sheet2!A1"）
+	Comment   *CellComment `json:"comment,omitempty"`   // 批注
+	Style     *CellStyle   `json:"style,omitempty"`     // 字体和填充样式（cell_metadata=style）
+}
+
+// CellComment 单元格批注
+type CellComment struct {
+	Author string `json:"author,omitempty"` // 作者
+	Text   string `json:"text"`             // 批注内容
+}
+
+// CellStyle 单元格的字体和填充样式
+type CellStyle struct {
+	Font *FontStyle `json:"font,omitempty"` // 字体
+	Fill *FillStyle `json:"fill,omitempty"` // 填充，没有填充时不输出
+}
+
+// FontStyle 字体样式
+type FontStyle struct {
+	Family    string  `json:"family,omitempty"`    // 字体名称
+	Size      float64 `json:"size,omitempty"`      // 字号
+	Bold      bool    `json:"bold,omitempty"`      // 粗体
+	Italic    bool    `json:"italic,omitempty"`    // 斜体
+	Underline string  `json:"underline,omitempty"` // 下划线类型，如 single、double
+	Strike    bool    `json:"strike,omitempty"`    // 删除线
+	Color     string  `json:"color,omitempty"`     // 颜色（RGB），主题颜色不输出
+}
+
+// FillStyle 填充样式
+type FillStyle struct {
+	Type    string   `json:"type"`              // 填充类型：pattern、gradient
+	Pattern int      `json:"pattern,omitempty"` // 图案填充的样式编号，1为纯色填充
+	Color   []string `json:"color,omitempty"`   // 填充颜色（RGB）
+}
+
+// Excel公式单元格的输出方式
+const (
+	FormulasCached    = "cached"    // 输出文件中缓存的计算结果
+	FormulasText      = "text"      // 输出公式文本，如 "=SUM(A1:A3)"
+	FormulasBoth      = "both"      // 输出包含计算结果和公式的对象
+	FormulasCalculate = "calculate" // 没有缓存的计算结果时重新计算公式
+)
+
+// 表格提取方式
+const (
+	TablesFirst = "first" // 只解析第一个表格
+	TablesAll   = "all"   // 按空行、空列分割工作表，解析所有表格
+)
+
+// TableRange 指定的表格范围，行号和列号从1开始，0表示未指定
+type TableRange struct {
+	HeaderRow int // 表头所在的行号
+	StartCol  int // 起始列号
+	EndRow    int // 最后一行的行号
+	EndCol    int // 最后一列的列号
+}
+
+// TableOrigin 表格的起始位置（表头行的第一个单元格），可作为后续请求的 header_row 和 start_col 参数
+type TableOrigin struct {
+	Cell      string `json:"cell"`       // A1格式的单元格，如 "B4"
+	HeaderRow int    `json:"header_row"` // 表头所在的行号（从1开始）
+	StartCol  int    `json:"start_col"`  // 起始列号（从1开始）
+	Detected  bool   `json:"detected"`   // 是否为自动检测的位置
+}
+
+// DateOptions 日期识别与输出参数
+type DateOptions struct {
+	Order    string         // 年份在末尾的纯数字日期的顺序（YMD、DMY、MDY），空字符串表示自动判断
+	Layout   string         // 输出格式（Go时间格式），空字符串表示按识别到的精度输出ISO 8601格式
+	Location *time.Location // 时区，nil表示不转换
+}
+
+// 单元格值输出方式
+const (
+	CellValuesTyped     = "typed"     // 按Excel单元格类型和数字格式输出类型化的值（日期为ISO格式，百分比、货币为数值，布尔值为true/false）
+	CellValuesFormatted = "formatted" // 按单元格的显示文本推断类型
+	CellValuesBoth      = "both"      // 每个单元格输出为包含类型化的值、原始值和显示文本的对象
+)
+
+// 表头键名的命名风格
+const (
+	HeaderCaseOriginal = "original" // 保持表头文本（去掉首尾空白，换行替换为空格）
+	HeaderCaseSnake    = "snake"    // snake_case，如 "Order ID" → "order_id"
+	HeaderCaseCamel    = "camel"    // camelCase，如 "Order ID" → "orderId"
+)
+
+// Excel合并单元格的填充方式
+const (
+	MergedCellsNone       = "none"        // 不填充，只有左上角单元格有值
+	MergedCellsFillDown   = "fill_down"   // 将值填充到合并区域第一列的各行
+	MergedCellsFillAcross = "fill_across" // 将值填充到合并区域第一行的各列
+	MergedCellsFillBoth   = "fill_both"   // 将值填充到整个合并区域
+)
+
+// CellValue cell_values=both时单元格的输出
+type CellValue struct {
+	Value     interface{} `json:"value"`             // 类型化的值
+	Raw       string      `json:"raw"`               // 单元格中保存的原始值（如日期序列号、0.25）
+	Formatted string      `json:"formatted"`         // 按数字格式显示的文本（如 2024/3/5、25.00%）
+	Formula   string      `json:"formula,omitempty"` // 公式单元格的公式（formulas为text或both时输出）
+}
+
+// FormulaValue formulas=both时公式单元格的输出
+type FormulaValue struct {
+	Value   interface{} `json:"value"`   // 缓存的计算结果，没有缓存时为null
+	Formula string      `json:"formula"` // 公式文本，如 "=SUM(A1:A3)"
+}
+
+// ListOptions 列表拆分参数
+type ListOptions struct {
+	Enabled    bool     // 是否拆分列表
+	Columns    []string // 只拆分这些列（表头名称或Col_N键名），为空时拆分所有列
+	Separators []string // 分隔符
+	DropEmpty  bool     // 是否去掉拆分后的空项目
+}
+
+// 数值转换模式
+const (
+	NumericModeAuto    = "auto"    // 整数转换为int64（溢出时为json.Number），小数转换为float64，带前导零的数字保持字符串
+	NumericModeString  = "string"  // 不转换数值，保持原始字符串
+	NumericModeFloat   = "float"   // 所有数值转换为float64（包括带前导零的数字）
+	NumericModeDecimal = "decimal" // 所有数值按原始精度输出为json.Number，带前导零的数字保持字符串
+)
+
+// CSVOptions CSV格式参数，零值表示自动检测
+type CSVOptions struct {
+	Delimiter  rune  // 分隔符
+	Quote      rune  // 引号字符
+	Comment    rune  // 注释行的起始字符
+	LazyQuotes *bool // 是否允许不规范的引号
+}
+
+// 文件类型的确定方式
+const (
+	FileTypeSourceRequest     = "request"      // 请求参数file_type指定
+	FileTypeSourceContent     = "content"      // 根据文件内容（文件头、文本特征）识别
+	FileTypeSourceContentType = "content_type" // 根据响应的Content-Type
+	FileTypeSourceExtension   = "extension"    // 根据URL或Content-Disposition中文件名的扩展名
+)
+
+// FileTypeInfo 响应中的文件类型及其确定方式
+type FileTypeInfo struct {
+	FileType       string `json:"file_type,omitempty"`        // 解析时使用的文件类型，如 "xlsx"
+	FileTypeSource string `json:"file_type_source,omitempty"` // 文件类型的确定方式
 }
 
 // ExcelResponse Excel解析响应
@@ -24,9 +265,25 @@ type ExcelResponse struct {
 
 // OrderedExcelResponse 按表头顺序输出的Excel解析响应
 type OrderedExcelResponse struct {
-	Data       []map[string]interface{} `json:"data"`
-	Headers    []string                 `json:"headers,omitempty"` // 表头顺序
-	OriginalHeaders []string            `json:"original_headers,omitempty"` // 原始表头（当使用统一格式键名时）
+	Data            []map[string]interface{}  `json:"data"`
+	Headers         []string                  `json:"headers,omitempty"`          // 表头顺序
+	OriginalHeaders []string                  `json:"original_headers,omitempty"` // 原始表头（当使用统一格式键名时）
+	Encoding        string                    `json:"encoding,omitempty"`         // 检测到的字符编码（仅CSV）
+	HeaderTree      []HeaderNode              `json:"header_tree,omitempty"`      // 多行表头的层级结构（只有一行表头时不输出）
+	TableOrigin     *TableOrigin              `json:"table_origin,omitempty"`     // 表格的起始位置
+	Range           string                    `json:"range,omitempty"`            // 表格区域（A1格式），只在tables=all时输出
+	Tables          []OrderedExcelResponse    `json:"tables,omitempty"`           // tables=all时工作表中的各个表格
+	Metadata        []map[string]CellMetadata `json:"metadata,omitempty"`         // 与data逐行对应的单元格附加信息（cell_metadata）
+	SkippedRows     []int                     `json:"skipped_rows,omitempty"`     // 跳过的隐藏行的行号（从1开始）
+	SkippedColumns  []string                  `json:"skipped_columns,omitempty"`  // 跳过的隐藏列的列名，如 "C"
+	FileTypeInfo                              // 文件类型（只在顶层响应中输出）
+}
+
+// HeaderNode 多行表头的层级结构节点
+type HeaderNode struct {
+	Name     string       `json:"name"`               // 表头单元格的文本
+	Key      string       `json:"key,omitempty"`      // 叶子节点对应的数据键名
+	Children []HeaderNode `json:"children,omitempty"` // 下一级表头
 }
 
 // 正则表达式匹配 Col_数字 格式
@@ -75,23 +332,49 @@ func (o OrderedJSONObject) MarshalJSON() ([]byte, error) {
 
 // MarshalJSON 自定义JSON序列化，确保按表头顺序输出
 func (r OrderedExcelResponse) MarshalJSON() ([]byte, error) {
+	if r.Tables != nil {
+		// tables=all时只输出各个表格
+		return json.Marshal(struct {
+			Tables         []OrderedExcelResponse `json:"tables"`
+			Encoding       string                 `json:"encoding,omitempty"`
+			SkippedRows    []int                  `json:"skipped_rows,omitempty"`
+			SkippedColumns []string               `json:"skipped_columns,omitempty"`
+			FileTypeInfo
+		}{Tables: r.Tables, Encoding: r.Encoding, SkippedRows: r.SkippedRows, SkippedColumns: r.SkippedColumns, FileTypeInfo: r.FileTypeInfo})
+	}
+
 	// 创建一个新的结构体用于输出
 	type Output struct {
-		Data []json.RawMessage `json:"data"`
-		Headers []string       `json:"headers,omitempty"`
-		OriginalHeaders []string `json:"original_headers,omitempty"`
+		Data            []json.RawMessage `json:"data"`
+		Headers         []string          `json:"headers,omitempty"`
+		OriginalHeaders []string          `json:"original_headers,omitempty"`
+		Encoding        string            `json:"encoding,omitempty"`
+		HeaderTree      []HeaderNode      `json:"header_tree,omitempty"`
+		TableOrigin     *TableOrigin      `json:"table_origin,omitempty"`
+		Range           string            `json:"range,omitempty"`
+		Metadata        []json.RawMessage `json:"metadata,omitempty"`
+		SkippedRows     []int             `json:"skipped_rows,omitempty"`
+		SkippedColumns  []string          `json:"skipped_columns,omitempty"`
+		FileTypeInfo
 	}
 
 	out := Output{
-		Headers: r.Headers,
+		Headers:         r.Headers,
 		OriginalHeaders: r.OriginalHeaders,
-		Data: make([]json.RawMessage, len(r.Data)),
+		Encoding:        r.Encoding,
+		HeaderTree:      r.HeaderTree,
+		TableOrigin:     r.TableOrigin,
+		Range:           r.Range,
+		SkippedRows:     r.SkippedRows,
+		SkippedColumns:  r.SkippedColumns,
+		FileTypeInfo:    r.FileTypeInfo,
+		Data:            make([]json.RawMessage, len(r.Data)),
 	}
 
 	// 检查是否需要对表头进行数字排序
 	if len(r.Headers) > 0 {
-		// 检查第一个表头是否是 Col_X 格式
-		if colPattern.MatchString(r.Headers[0]) {
+		// 检查表头是否都是 Col_X 格式（空表头生成的 Col_X 与其他表头混合时保持原始顺序）
+		if allColHeaders(r.Headers) {
 			// 如果是 Col_X 格式，按数字排序表头
 			sortedHeaders := make([]string, len(r.Headers))
 			copy(sortedHeaders, r.Headers)
@@ -129,9 +412,38 @@ func (r OrderedExcelResponse) MarshalJSON() ([]byte, error) {
 		out.Data[i] = jsonData
 	}
 
+	// 附加信息与data逐行对应，只输出有附加信息的单元格，同样按表头顺序
+	if r.Metadata != nil {
+		out.Metadata = make([]json.RawMessage, len(r.Metadata))
+		for i, item := range r.Metadata {
+			orderedObj := OrderedJSONObject{Values: make(map[string]interface{}, len(item))}
+			for _, key := range out.Headers {
+				if meta, ok := item[key]; ok {
+					orderedObj.Keys = append(orderedObj.Keys, key)
+					orderedObj.Values[key] = meta
+				}
+			}
+			jsonData, err := json.Marshal(orderedObj)
+			if err != nil {
+				return nil, err
+			}
+			out.Metadata[i] = jsonData
+		}
+	}
+
 	return json.Marshal(out)
 }
 
+// allColHeaders 检查表头是否都是 Col_X 格式
+func allColHeaders(headers []string) bool {
+	for _, header := range headers {
+		if !colPattern.MatchString(header) {
+			return false
+		}
+	}
+	return true
+}
+
 // extractColNumber 从 Col_X 格式的字符串中提取数字部分
 func extractColNumber(colStr string) int {
 	matches := colPattern.FindStringSubmatch(colStr)
@@ -144,14 +456,113 @@ func extractColNumber(colStr string) int {
 	return 0
 }
 
+// MultiSheetResponse 多工作表解析响应，按工作簿中的顺序以工作表名称为键输出
+type MultiSheetResponse struct {
+	SheetNames    []string                        // 工作表名称（按工作簿顺序）
+	Sheets        map[string]OrderedExcelResponse // 各工作表的解析结果
+	SheetErrors   map[string]string               // 解析失败的工作表的错误信息
+	SkippedSheets []string                        // 跳过的隐藏工作表
+	FileTypeInfo                                  // 文件类型
+}
+
+// MarshalJSON 自定义JSON序列化，确保工作表按工作簿中的顺序输出
+// 解析失败的工作表不在sheets中输出，错误信息按工作表名称输出到sheet_errors
+func (r MultiSheetResponse) MarshalJSON() ([]byte, error) {
+	sheets := OrderedJSONObject{Values: make(map[string]interface{}, len(r.Sheets))}
+	var sheetErrors *OrderedJSONObject
+	for _, name := range r.SheetNames {
+		if sheet, ok := r.Sheets[name]; ok {
+			sheets.Keys = append(sheets.Keys, name)
+			sheets.Values[name] = sheet
+		} else if message, ok := r.SheetErrors[name]; ok {
+			if sheetErrors == nil {
+				sheetErrors = &OrderedJSONObject{Values: make(map[string]interface{}, len(r.SheetErrors))}
+			}
+			sheetErrors.Keys = append(sheetErrors.Keys, name)
+			sheetErrors.Values[name] = message
+		}
+	}
+
+	return json.Marshal(struct {
+		Sheets        OrderedJSONObject  `json:"sheets"`
+		SheetErrors   *OrderedJSONObject `json:"sheet_errors,omitempty"`
+		SkippedSheets []string           `json:"skipped_sheets,omitempty"`
+		FileTypeInfo
+	}{Sheets: sheets, SheetErrors: sheetErrors, SkippedSheets: r.SkippedSheets, FileTypeInfo: r.FileTypeInfo})
+}
+
+// SheetInfo 工作表信息
+type SheetInfo struct {
+	Name      string `json:"name"`      // 工作表名称
+	Index     int    `json:"index"`     // 工作表索引（从0开始）
+	Visible   bool   `json:"visible"`   // 是否可见
+	Dimension string `json:"dimension"` // 已使用的单元格范围，如 A1:K200
+	Rows      int    `json:"rows"`      // 行数（根据数据范围计算）
+	Cols      int    `json:"cols"`      // 列数（根据数据范围计算）
+}
+
+// SheetListResponse 工作表列表响应
+type SheetListResponse struct {
+	Sheets []SheetInfo `json:"sheets"`
+	FileTypeInfo
+}
+
+// DefinedTableInfo Excel表格（插入 > 表格）信息
+type DefinedTableInfo struct {
+	Name  string `json:"name"`  // 表格名称
+	Sheet string `json:"sheet"` // 所在的工作表
+	Range string `json:"range"` // 表格区域（包括表头行），如 A1:D10
+}
+
+// DefinedNameInfo Excel名称信息
+type DefinedNameInfo struct {
+	Name     string `json:"name"`            // 名称
+	Scope    string `json:"scope"`           // 作用范围："Workbook"或工作表名称
+	RefersTo string `json:"refers_to"`       // 引用的内容，如 Sheet1!$A$1:$D$10
+	Sheet    string `json:"sheet,omitempty"` // 引用的工作表（只有引用单个区域时输出）
+	Range    string `json:"range,omitempty"` // 引用的区域（只有引用单个区域时输出）
+}
+
+// TableListResponse 表格和名称列表响应
+type TableListResponse struct {
+	Tables []DefinedTableInfo `json:"tables"`
+	Names  []DefinedNameInfo  `json:"names"`
+	FileTypeInfo
+}
+
 // TextResponse 文本解析响应
 type TextResponse struct {
-	Content string `json:"content"`
+	Content  string `json:"content"`
+	Encoding string `json:"encoding,omitempty"` // 检测到的字符编码（仅Go直接处理的文本文件）
+	FileTypeInfo
 }
 
 // ErrorResponse 错误响应
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error       string `json:"error"`
+	Code        string `json:"code,omitempty"`          // 错误代码，如URL被出站策略拒绝时的 URL_PRIVATE_ADDRESS
+	MaxFileSize int64  `json:"max_file_size,omitempty"` // 文件太大时的最大文件大小（字节）
+	FileSize    int64  `json:"file_size,omitempty"`     // 文件太大时的文件大小（字节），未知实际大小时为已读取的字节数
+}
+
+// OutboundPolicy 下载文件时的出站URL策略
+type OutboundPolicy struct {
+	AllowedSchemes  []string // 允许的协议，如 http、https
+	AllowedHosts    []string // 允许的主机，为空表示不限制，支持 *.example.com 形式的通配符
+	DeniedHosts     []string // 禁止的主机，优先于允许的主机，支持通配符
+	AllowedPorts    []int    // 允许的端口，为空表示不限制
+	AllowPrivateIPs bool     // 是否允许连接内网、本机、链路本地等地址
+}
+
+// DownloadConfig 下载文件的超时、重定向和重试设置
+type DownloadConfig struct {
+	ConnectTimeout time.Duration // 建立TCP连接的超时
+	HeaderTimeout  time.Duration // 发送请求后等待响应头的超时
+	Timeout        time.Duration // 每次下载（包括重定向和读取响应体）的总超时
+	MaxRedirects   int           // 最大重定向次数
+	MaxRetries     int           // 连接失败、超时或502等临时错误时的最大重试次数
+	RetryDelay     time.Duration // 第一次重试前的等待时间，之后每次翻倍
+	RetryMaxDelay  time.Duration // 重试前的最大等待时间，Retry-After超过该时间时不再重试
 }
 
 // FileInfo 文件信息
@@ -160,6 +571,7 @@ type FileInfo struct {
 	FileType    string
 	ContentType string
 	Size        int64
+	TypeSource  string // 文件类型的确定方式，见FileTypeSource常量
 }
 
 // IsExcel 判断是否为Excel文件
@@ -182,7 +594,7 @@ func (f *FileInfo) IsText() bool {
 	return f.FileType == ".txt" || f.FileType == ".md"
 }
 
-// IsCSV 判断是否为CSV文件
+// IsCSV 判断是否为CSV文件（包括制表符分隔的.tsv和竖线分隔的.psv）
 func (f *FileInfo) IsCSV() bool {
-	return f.FileType == ".csv"
+	return f.FileType == ".csv" || f.FileType == ".tsv" || f.FileType == ".psv"
 }
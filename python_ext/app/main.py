@@ -75,19 +75,41 @@ async def parse_file(
         if temp_file_path and os.path.exists(temp_file_path):
             os.unlink(temp_file_path)
 
+# 常用汉字，GB18030几乎能解码任何字节序列，解码结果中没有常用字时不按GB18030解码
+COMMON_CHINESE_CHARS = set(
+    "的一是不了人我在有他这中大来上国个到说们为子和你地出道也时年得就那要下以生会自着去之过家学对可她里后小么心多天而能好都然没日于起还发成事只作当想看文无开手十用主行方又如前所本见经头面公同三已老从动两长号码单价数量金额合计备注客户订单产品名称日期编类型状态"
+)
+
+def read_text_file(file_path: str) -> str:
+    """读取文本文件，按BOM、UTF-8、GB18030的顺序尝试解码，都失败或GB18030解码结果中没有常用汉字时按Latin-1解码"""
+    with open(file_path, 'rb') as file:
+        data = file.read()
+
+    if data.startswith(b'\xff\xfe') or data.startswith(b'\xfe\xff'):
+        return data.decode('utf-16')
+    try:
+        return data.decode('utf-8-sig')
+    except UnicodeDecodeError:
+        pass
+    try:
+        text = data.decode('gb18030')
+        if any(char in COMMON_CHINESE_CHARS for char in text):
+            return text
+    except UnicodeDecodeError:
+        pass
+    return data.decode('latin-1')
+
 def parse_text(file_path: str) -> str:
     """解析文本文件"""
-    with open(file_path, 'r', encoding='utf-8', errors='replace') as file:
-        return file.read()
+    return read_text_file(file_path)
 
 def parse_markdown(file_path: str) -> str:
     """解析Markdown文件"""
-    with open(file_path, 'r', encoding='utf-8', errors='replace') as file:
-        md_content = file.read()
-        # 可选：将Markdown转换为HTML
-        # html_content = markdown.markdown(md_content)
-        # return html_content
-        return md_content
+    md_content = read_text_file(file_path)
+    # 可选：将Markdown转换为HTML
+    # html_content = markdown.markdown(md_content)
+    # return html_content
+    return md_content
 
 def parse_docx(file_path: str) -> str:
     """解析Word文档(.docx格式)"""
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	"file-url-parser/model"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// errCellMetadataXLS .xls文件不支持读取单元格附加信息
+var errCellMetadataXLS = errors.New("只有.xlsx文件支持cell_metadata参数")
+
+// cellMetadataReader 读取.xlsx工作表中单元格的超链接、批注和样式
+// 批注在创建时一次读取，超链接和样式按单元格读取（会加载整个工作表），只处理实际读取的行
+type cellMetadataReader struct {
+	file      *excelize.File
+	sheetName string
+	withStyle bool                               // 是否读取字体和填充样式
+	comments  map[int]map[int]*model.CellComment // 行索引 -> 列索引 -> 批注（从0开始）
+	styles    map[int]*model.CellStyle           // 样式ID -> 字体和填充样式
+}
+
+// newCellMetadataReader 创建单元格附加信息读取器
+func newCellMetadataReader(f *excelize.File, sheetName string, mode string) (*cellMetadataReader, error) {
+	comments, err := f.GetComments(sheetName)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &cellMetadataReader{
+		file:      f,
+		sheetName: sheetName,
+		withStyle: mode == model.CellMetadataStyle,
+		comments:  make(map[int]map[int]*model.CellComment),
+		styles:    make(map[int]*model.CellStyle),
+	}
+	for _, comment := range comments {
+		col, row, err := excelize.CellNameToCoordinates(comment.Cell)
+		if err != nil {
+			continue
+		}
+		text := comment.Text
+		for _, run := range comment.Paragraph {
+			text += run.Text
+		}
+		// Excel在批注开头插入 "作者:" 和换行
+		if comment.Author != "" {
+			text = strings.TrimPrefix(text, comment.Author+":")
+		}
+		if reader.comments[row-1] == nil {
+			reader.comments[row-1] = make(map[int]*model.CellComment)
+		}
+		reader.comments[row-1][col-1] = &model.CellComment{Author: comment.Author, Text: strings.TrimSpace(text)}
+	}
+	return reader, nil
+}
+
+// read 读取第rowNum行（从0开始）有内容或有批注的单元格的附加信息，写入cells中对应的单元格
+func (r *cellMetadataReader) read(rowNum int, row []string, cells []typedCell) ([]typedCell, error) {
+	comments := r.comments[rowNum]
+	width := len(row)
+	for col := range comments {
+		width = max(width, col+1)
+	}
+
+	for col := 0; col < width; col++ {
+		comment := comments[col]
+		if cellText(row, col) == "" && comment == nil {
+			continue
+		}
+		cellName, err := excelize.CoordinatesToCellName(col+1, rowNum+1)
+		if err != nil {
+			return nil, err
+		}
+
+		meta := &model.CellMetadata{Cell: cellName, Comment: comment}
+		if _, meta.Hyperlink, err = r.file.GetCellHyperLink(r.sheetName, cellName); err != nil {
+			return nil, err
+		}
+		if r.withStyle {
+			if meta.Style, err = r.cellStyle(cellName); err != nil {
+				return nil, err
+			}
+		}
+		if meta.Hyperlink == "" && meta.Comment == nil && meta.Style == nil {
+			continue
+		}
+
+		for len(cells) <= col {
+			cells = append(cells, typedCell{})
+		}
+		cells[col].metadata = meta
+	}
+	return cells, nil
+}
+
+// cellStyle 获取单元格的字体和填充样式，使用默认样式时返回nil
+func (r *cellMetadataReader) cellStyle(cellName string) (*model.CellStyle, error) {
+	styleID, err := r.file.GetCellStyle(r.sheetName, cellName)
+	if err != nil || styleID == 0 {
+		return nil, err
+	}
+	if style, ok := r.styles[styleID]; ok {
+		return style, nil
+	}
+
+	s, err := r.file.GetStyle(styleID)
+	if err != nil {
+		return nil, err
+	}
+	style := &model.CellStyle{}
+	if s.Font != nil {
+		style.Font = &model.FontStyle{
+			Family:    s.Font.Family,
+			Size:      s.Font.Size,
+			Bold:      s.Font.Bold,
+			Italic:    s.Font.Italic,
+			Underline: s.Font.Underline,
+			Strike:    s.Font.Strike,
+			Color:     s.Font.Color,
+		}
+	}
+	if s.Fill.Type != "" && (s.Fill.Pattern > 0 || len(s.Fill.Color) > 0) {
+		style.Fill = &model.FillStyle{Type: s.Fill.Type, Pattern: s.Fill.Pattern, Color: s.Fill.Color}
+	}
+	if style.Font == nil && style.Fill == nil {
+		style = nil
+	}
+	r.styles[styleID] = style
+	return style, nil
+}
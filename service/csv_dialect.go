@@ -0,0 +1,328 @@
+package service
+
+import (
+	"bufio"
+	"errors"
+	"file-url-parser/model"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// dialectSampleSize 检测CSV格式时读取的样本大小
+const dialectSampleSize = 64 * 1024
+
+// dialectSampleRecords 检测CSV格式时最多分析的记录数
+const dialectSampleRecords = 50
+
+// candidateDelimiters 自动检测时尝试的分隔符，顺序即平局时的优先级
+var candidateDelimiters = []rune{',', ';', '\t', '|'}
+
+var (
+	errBareQuote       = errors.New("未加引号的字段中出现引号")
+	errExtraneousQuote = errors.New("引号字段中出现多余的引号")
+	errMissingQuote    = errors.New("引号字段缺少结束引号")
+)
+
+// csvDialect CSV文件格式参数
+type csvDialect struct {
+	delimiter  rune
+	quote      rune // 0表示不处理引号
+	comment    rune // 0表示没有注释行
+	lazyQuotes bool
+	sepLine    bool // 第一行是Excel的分隔符声明（如 sep=;），读取时跳过
+}
+
+// delimitedReader 按指定格式逐条读取分隔符文本的记录
+// 支持任意分隔符和引号字符，引号字段中可以包含分隔符、换行以及连续两个引号表示的引号本身
+type delimitedReader struct {
+	reader         *bufio.Reader
+	dialect        csvDialect
+	line           int
+	keepEmptyLines bool // 是否将空行作为没有字段的记录返回（分割多个表格时作为边界）
+}
+
+// newDelimitedReader 创建分隔符文本读取器
+func newDelimitedReader(reader io.Reader, dialect csvDialect) *delimitedReader {
+	bufReader, ok := reader.(*bufio.Reader)
+	if !ok {
+		bufReader = bufio.NewReader(reader)
+	}
+	return &delimitedReader{reader: bufReader, dialect: dialect}
+}
+
+// Next 读取下一条记录，跳过空行（keepEmptyLines为false时）、注释行和分隔符声明行
+func (r *delimitedReader) Next() ([]string, error) {
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if r.line == 1 && r.dialect.sepLine {
+			continue
+		}
+		if line == "" {
+			if r.keepEmptyLines {
+				return []string{}, nil
+			}
+			continue
+		}
+		if r.dialect.comment != 0 && strings.HasPrefix(line, string(r.dialect.comment)) {
+			continue
+		}
+		return r.parseRecord(line)
+	}
+}
+
+// Skip 跳过下一条记录
+func (r *delimitedReader) Skip() error {
+	_, err := r.Next()
+	return err
+}
+
+// readLine 读取一行并去掉行尾的换行符
+func (r *delimitedReader) readLine() (string, error) {
+	line, err := r.reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	r.line++
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	return line, nil
+}
+
+// parseRecord 解析一条记录，引号字段跨行时继续读取后续行
+func (r *delimitedReader) parseRecord(line string) ([]string, error) {
+	delimiter, quote := string(r.dialect.delimiter), string(r.dialect.quote)
+	startLine := r.line
+
+	var fields []string
+	for {
+		if r.dialect.quote != 0 && strings.HasPrefix(line, quote) {
+			// 引号字段
+			var field strings.Builder
+			line = line[len(quote):]
+			for {
+				i := strings.Index(line, quote)
+				if i < 0 {
+					// 字段在下一行继续
+					field.WriteString(line)
+					next, err := r.readLine()
+					if err == io.EOF {
+						if !r.dialect.lazyQuotes {
+							return nil, fmt.Errorf("第 %d 行: %w", startLine, errMissingQuote)
+						}
+						line = ""
+						break
+					}
+					if err != nil {
+						return nil, err
+					}
+					field.WriteByte('\n')
+					line = next
+					continue
+				}
+
+				field.WriteString(line[:i])
+				line = line[i+len(quote):]
+				switch {
+				case strings.HasPrefix(line, quote):
+					// 连续两个引号表示引号本身
+					field.WriteString(quote)
+					line = line[len(quote):]
+					continue
+				case line == "" || strings.HasPrefix(line, delimiter):
+					// 字段结束
+				case r.dialect.lazyQuotes:
+					// 宽松模式下多余的引号作为普通字符；本行没有后续的引号时字段到下一个分隔符结束，不吞掉后续的行
+					field.WriteString(quote)
+					if !strings.Contains(line, quote) {
+						end := strings.Index(line, delimiter)
+						if end < 0 {
+							end = len(line)
+						}
+						field.WriteString(line[:end])
+						line = line[end:]
+						break
+					}
+					continue
+				default:
+					return nil, fmt.Errorf("第 %d 行: %w", r.line, errExtraneousQuote)
+				}
+				break
+			}
+			fields = append(fields, field.String())
+			if line == "" {
+				return fields, nil
+			}
+			line = line[len(delimiter):]
+			continue
+		}
+
+		// 普通字段
+		i := strings.Index(line, delimiter)
+		field := line
+		if i >= 0 {
+			field = line[:i]
+		}
+		if r.dialect.quote != 0 && !r.dialect.lazyQuotes && strings.Contains(field, quote) {
+			return nil, fmt.Errorf("第 %d 行: %w", r.line, errBareQuote)
+		}
+		fields = append(fields, field)
+		if i < 0 {
+			return fields, nil
+		}
+		line = line[i+len(delimiter):]
+	}
+}
+
+// detectCSVDialect 根据样本数据检测CSV格式，请求中指定的参数优先
+// fileType用于在平局时优先选择扩展名对应的分隔符（.tsv为制表符，.psv为竖线）
+func detectCSVDialect(sample []byte, truncated bool, fileType string, options model.CSVOptions) csvDialect {
+	text := string(sample)
+	if truncated {
+		// 样本末尾可能是不完整的行
+		if idx := strings.LastIndexByte(text, '\n'); idx >= 0 {
+			text = text[:idx+1]
+		}
+	}
+
+	dialect := csvDialect{
+		delimiter: options.Delimiter,
+		quote:     options.Quote,
+		comment:   options.Comment,
+	}
+
+	// Excel导出的文件第一行可以声明分隔符，声明行不是数据，不参与检测
+	if delimiter, ok := sepLineDelimiter(text); ok {
+		dialect.sepLine = true
+		if dialect.delimiter == 0 {
+			dialect.delimiter = delimiter
+		}
+		_, text, _ = strings.Cut(text, "\n")
+	}
+
+	// 检测引号字符
+	if dialect.quote == 0 {
+		dialect.quote = detectQuote(text)
+	}
+
+	// 检测分隔符
+	if dialect.delimiter == 0 {
+		candidates := candidateDelimiters
+		if preferred := extensionDelimiter(fileType); preferred != 0 {
+			candidates = append([]rune{preferred}, candidateDelimiters...)
+		}
+		bestScore, bestFields := 0, 0
+		dialect.delimiter = candidates[0]
+		for _, delimiter := range candidates {
+			score, fields := scoreDelimiter(text, csvDialect{
+				delimiter:  delimiter,
+				quote:      dialect.quote,
+				comment:    dialect.comment,
+				lazyQuotes: true,
+			})
+			if score > bestScore || (score == bestScore && fields > bestFields) {
+				dialect.delimiter, bestScore, bestFields = delimiter, score, fields
+			}
+		}
+	}
+
+	// 检测是否需要宽松引号模式：样本按严格模式解析失败时启用
+	if options.LazyQuotes != nil {
+		dialect.lazyQuotes = *options.LazyQuotes
+	} else {
+		dialect.lazyQuotes = !parsesStrictly(text, dialect)
+	}
+
+	return dialect
+}
+
+// sepLineDelimiter 返回第一行中Excel分隔符声明（如 sep=; 或 "sep=;"）指定的分隔符
+func sepLineDelimiter(text string) (rune, bool) {
+	line, _, _ := strings.Cut(strings.TrimPrefix(text, "\ufeff"), "\n")
+	line = strings.Trim(strings.TrimSuffix(line, "\r"), `"`)
+	if len(line) < 4 || !strings.EqualFold(line[:4], "sep=") {
+		return 0, false
+	}
+	delimiter := []rune(line[4:])
+	if len(delimiter) != 1 {
+		return 0, false
+	}
+	return delimiter[0], true
+}
+
+// extensionDelimiter 根据扩展名返回默认分隔符
+func extensionDelimiter(fileType string) rune {
+	switch strings.ToLower(fileType) {
+	case ".tsv":
+		return '\t'
+	case ".psv":
+		return '|'
+	}
+	return 0
+}
+
+// detectQuote 检测引号字符：只有出现单引号包围的字段且没有双引号包围的字段时才使用单引号
+func detectQuote(text string) rune {
+	if countQuotedFields(text, '"') == 0 && countQuotedFields(text, '\'') > 0 {
+		return '\''
+	}
+	return '"'
+}
+
+// countQuotedFields 统计以引号开头并以引号结尾的字段数量
+func countQuotedFields(text string, quote rune) int {
+	count := 0
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		for _, delimiter := range candidateDelimiters {
+			for _, field := range strings.Split(line, string(delimiter)) {
+				if len(field) >= 2 && strings.HasPrefix(field, string(quote)) && strings.HasSuffix(field, string(quote)) {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
+// scoreDelimiter 计算分隔符的得分：字段数最常见（且大于1）的记录条数，以及该字段数
+func scoreDelimiter(text string, dialect csvDialect) (int, int) {
+	reader := newDelimitedReader(strings.NewReader(text), dialect)
+	counts := make(map[int]int)
+	for i := 0; i < dialectSampleRecords; i++ {
+		record, err := reader.Next()
+		if err != nil {
+			break
+		}
+		counts[len(record)]++
+	}
+
+	bestScore, bestFields := 0, 0
+	for fields, score := range counts {
+		if fields <= 1 {
+			continue
+		}
+		if score > bestScore || (score == bestScore && fields > bestFields) {
+			bestScore, bestFields = score, fields
+		}
+	}
+	return bestScore, bestFields
+}
+
+// parsesStrictly 检查样本能否按严格的引号规则解析
+func parsesStrictly(text string, dialect csvDialect) bool {
+	dialect.lazyQuotes = false
+	reader := newDelimitedReader(strings.NewReader(text), dialect)
+	for i := 0; i < dialectSampleRecords; i++ {
+		if _, err := reader.Next(); err != nil {
+			return err == io.EOF || errors.Is(err, errMissingQuote)
+		}
+	}
+	return true
+}
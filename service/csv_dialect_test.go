@@ -0,0 +1,184 @@
+package service
+
+import (
+	"errors"
+	"file-url-parser/model"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readRecords 按检测到的格式读取所有记录
+func readRecords(text string, dialect csvDialect) ([][]string, error) {
+	reader := newDelimitedReader(strings.NewReader(text), dialect)
+	var records [][]string
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
+
+// TestDetectCSVDialect 检测分隔符、宽松引号模式和分隔符声明行，并按检测结果读取记录
+func TestDetectCSVDialect(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		fileType  string
+		options   model.CSVOptions
+		delimiter rune
+		lazy      bool
+		want      [][]string
+	}{
+		{
+			name:      "引号字段中的换行和引号",
+			text:      "name,note\n\"a\",\"line1\nline2\"\n\"b\",\"say \"\"hi\"\"\"\n",
+			delimiter: ',',
+			want:      [][]string{{"name", "note"}, {"a", "line1\nline2"}, {"b", `say "hi"`}},
+		},
+		{
+			name:      "CRLF换行",
+			text:      "name,note\r\na,\"x\r\ny\"\r\nb,2\r\n",
+			delimiter: ',',
+			want:      [][]string{{"name", "note"}, {"a", "x\ny"}, {"b", "2"}},
+		},
+		{
+			name:      "LF换行",
+			text:      "name,note\na,\"x\ny\"\nb,2\n",
+			delimiter: ',',
+			want:      [][]string{{"name", "note"}, {"a", "x\ny"}, {"b", "2"}},
+		},
+		{
+			name:      "未加引号的字段中出现引号",
+			text:      "name,size\nbolt,5\" long\nnut,3\n",
+			delimiter: ',',
+			lazy:      true,
+			want:      [][]string{{"name", "size"}, {"bolt", `5" long`}, {"nut", "3"}},
+		},
+		{
+			name:      "引号字段中多余的引号",
+			text:      "name,size\n\"bolt\"x,5\nnut,3\n",
+			delimiter: ',',
+			lazy:      true,
+			want:      [][]string{{"name", "size"}, {`bolt"x`, "5"}, {"nut", "3"}},
+		},
+		{
+			name:      "引号字段中成对的多余引号",
+			text:      "name,size\n\"a \"b\" c\",1\nd,2\n",
+			delimiter: ',',
+			lazy:      true,
+			want:      [][]string{{"name", "size"}, {`a "b" c`, "1"}, {"d", "2"}},
+		},
+		{
+			name:      "小数逗号的分号分隔文件",
+			text:      "name;price;qty\napple;1,5;3\npear;2,25;4\nplum;0,5;10\n",
+			delimiter: ';',
+			want:      [][]string{{"name", "price", "qty"}, {"apple", "1,5", "3"}, {"pear", "2,25", "4"}, {"plum", "0,5", "10"}},
+		},
+		{
+			name:      "逗号分隔文件中带分号的文本",
+			text:      "name,note\na,x;y\nb,z\n",
+			delimiter: ',',
+			want:      [][]string{{"name", "note"}, {"a", "x;y"}, {"b", "z"}},
+		},
+		{
+			name:      "制表符分隔",
+			text:      "name\tprice\tnote\napple\t1,5\ta, b\npear\t2\tc\n",
+			delimiter: '\t',
+			want:      [][]string{{"name", "price", "note"}, {"apple", "1,5", "a, b"}, {"pear", "2", "c"}},
+		},
+		{
+			name:      "tsv扩展名优先",
+			text:      "a\tb,c\n",
+			fileType:  ".tsv",
+			delimiter: '\t',
+			want:      [][]string{{"a", "b,c"}},
+		},
+		{
+			name:      "注释行",
+			text:      "# 导出时间 2024-01-01, 共2行\nname,qty\n# 小计\na,1\n",
+			options:   model.CSVOptions{Comment: '#'},
+			delimiter: ',',
+			want:      [][]string{{"name", "qty"}, {"a", "1"}},
+		},
+		{
+			name:      "分隔符声明行",
+			text:      "sep=;\nname;price\napple;1,5\n",
+			delimiter: ';',
+			want:      [][]string{{"name", "price"}, {"apple", "1,5"}},
+		},
+		{
+			name:      "带引号和CRLF的分隔符声明行",
+			text:      "\"SEP=|\"\r\nname|note\r\na|x,y\r\n",
+			delimiter: '|',
+			want:      [][]string{{"name", "note"}, {"a", "x,y"}},
+		},
+		{
+			name:      "请求参数优先于分隔符声明",
+			text:      "sep=;\nname,price\napple,1\n",
+			options:   model.CSVOptions{Delimiter: ','},
+			delimiter: ',',
+			want:      [][]string{{"name", "price"}, {"apple", "1"}},
+		},
+		{
+			name:      "不是分隔符声明的第一行",
+			text:      "sep=ab,x\n1,2\n",
+			delimiter: ',',
+			want:      [][]string{{"sep=ab", "x"}, {"1", "2"}},
+		},
+		{
+			name:      "单列",
+			text:      "name\nalice\nbob\n",
+			delimiter: ',',
+			want:      [][]string{{"name"}, {"alice"}, {"bob"}},
+		},
+		{
+			name:      "引号中带逗号的单列",
+			text:      "name\n\"Smith, John\"\n\"Doe, Jane\"\n",
+			delimiter: ',',
+			want:      [][]string{{"name"}, {"Smith, John"}, {"Doe, Jane"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialect := detectCSVDialect([]byte(tt.text), false, tt.fileType, tt.options)
+			if dialect.delimiter != tt.delimiter {
+				t.Errorf("分隔符为 %q，应为 %q", dialect.delimiter, tt.delimiter)
+			}
+			if dialect.lazyQuotes != tt.lazy {
+				t.Errorf("宽松引号模式为 %v，应为 %v", dialect.lazyQuotes, tt.lazy)
+			}
+			records, err := readRecords(tt.text, dialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(records, tt.want) {
+				t.Errorf("读取的记录为 %q，应为 %q", records, tt.want)
+			}
+		})
+	}
+}
+
+// TestDelimitedReaderStrictQuotes 严格引号模式下不规范的引号返回错误
+func TestDelimitedReaderStrictQuotes(t *testing.T) {
+	dialect := csvDialect{delimiter: ',', quote: '"'}
+	tests := []struct {
+		text string
+		want error
+	}{
+		{"a,5\" long\n", errBareQuote},
+		{"\"a\"x,1\n", errExtraneousQuote},
+		{"\"a,1\nb,2\n", errMissingQuote},
+	}
+	for _, tt := range tests {
+		if _, err := readRecords(tt.text, dialect); !errors.Is(err, tt.want) {
+			t.Errorf("%q: 错误为 %v，应为 %v", tt.text, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"errors"
+	"file-url-parser/model"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// datePrecision 日期值的精度，决定默认的输出格式
+type datePrecision int
+
+const (
+	precisionYear   datePrecision = iota // 年，输出 2006
+	precisionMonth                       // 年月，输出 2006-01
+	precisionDay                         // 年月日，输出 2006-01-02
+	precisionHour                        // 到小时，输出 2006-01-02 15
+	precisionMinute                      // 到分钟，输出 2006-01-02 15:04
+	precisionSecond                      // 到秒，输出 2006-01-02 15:04:05
+	precisionTime                        // 只有时间，输出 15:04:05
+)
+
+// precisionLayouts 各精度的默认输出格式（ISO 8601）
+var precisionLayouts = map[datePrecision]string{
+	precisionYear:   "2006",
+	precisionMonth:  "2006-01",
+	precisionDay:    "2006-01-02",
+	precisionHour:   "2006-01-02 15",
+	precisionMinute: "2006-01-02 15:04",
+	precisionSecond: "2006-01-02 15:04:05",
+	precisionTime:   "15:04:05",
+}
+
+// namedDateLayouts date_layout参数支持的格式名称
+var namedDateLayouts = map[string]string{
+	"iso":      "", // 按识别到的精度输出
+	"date":     "2006-01-02",
+	"datetime": "2006-01-02 15:04:05",
+	"rfc3339":  time.RFC3339,
+}
+
+// 日期顺序，用于解析年份不在开头的纯数字日期（如 05.04.2023）
+const (
+	dateOrderYMD = "YMD" // 只识别年份在开头的纯数字日期
+	dateOrderDMY = "DMY"
+	dateOrderMDY = "MDY"
+)
+
+// dateValue 识别出的日期值
+type dateValue struct {
+	time      time.Time
+	precision datePrecision
+	zoned     bool // 原始文本是否带有时区偏移
+}
+
+// dateLayout 日期格式及其精度
+type dateLayout struct {
+	layout    string
+	precision datePrecision
+	zoned     bool
+}
+
+// maxDateLength 参与日期识别的文本最大长度
+const maxDateLength = 64
+
+var (
+	// isoWeekPattern ISO周日期，如 2023-W14、2023-W14-3、2023W143
+	isoWeekPattern = regexp.MustCompile(`^(\d{4})-?W(\d{2})(?:-?([1-7]))?$`)
+	// cjkDateReplacer 将日文、韩文和繁体的日期时间标记统一为简体中文标记
+	cjkDateReplacer = strings.NewReplacer(
+		"년", "年", "월", "月", "일", "日", "號", "日", "号", "日",
+		"時", "时", "點", "时", "点", "时", "시", "时", "분", "分", "초", "秒",
+	)
+)
+
+// yearFirstLayouts 年份在开头的日期格式，按优先级排列
+var yearFirstLayouts = buildYearFirstLayouts()
+
+// monthNameLayouts 使用英文月份名称的日期格式
+var monthNameLayouts = []dateLayout{
+	{"Jan 2, 2006", precisionDay, false},
+	{"Jan 2 2006", precisionDay, false},
+	{"January 2, 2006", precisionDay, false},
+	{"January 2 2006", precisionDay, false},
+	{"2 Jan 2006", precisionDay, false},
+	{"2 January 2006", precisionDay, false},
+	{"2-Jan-2006", precisionDay, false},
+	{"2-Jan-06", precisionDay, false},
+	{"Mon, Jan 2, 2006", precisionDay, false},
+	{"Monday, January 2, 2006", precisionDay, false},
+	{"Mon, 2 Jan 2006", precisionDay, false},
+	{"Mon Jan 2 2006", precisionDay, false},
+	{"Jan 2, 2006 15:04:05", precisionSecond, false},
+	{"Jan 2, 2006 15:04", precisionMinute, false},
+	{"Jan 2, 2006 3:04 PM", precisionMinute, false},
+	{"January 2, 2006 15:04", precisionMinute, false},
+	{"January 2, 2006 3:04 PM", precisionMinute, false},
+	{"2 Jan 2006 15:04:05", precisionSecond, false},
+	{"2 Jan 2006 15:04", precisionMinute, false},
+	{"Mon, 2 Jan 2006 15:04:05 -0700", precisionSecond, true},
+	{"Jan 2006", precisionMonth, false},
+	{"January 2006", precisionMonth, false},
+	{"Jan-2006", precisionMonth, false},
+}
+
+// cjkLayouts 中日韩年月日格式，匹配前会统一标记字符并去掉空白
+var cjkLayouts = []dateLayout{
+	{"2006年1月2日15时4分5秒", precisionSecond, false},
+	{"2006年1月2日15时4分", precisionMinute, false},
+	{"2006年1月2日15时", precisionHour, false},
+	{"2006年1月2日15:04:05", precisionSecond, false},
+	{"2006年1月2日15:04", precisionMinute, false},
+	{"2006年1月2日", precisionDay, false},
+	{"2006年1月", precisionMonth, false},
+	{"2006年", precisionYear, false},
+}
+
+// buildYearFirstLayouts 生成年份在开头的纯数字日期格式
+func buildYearFirstLayouts() []dateLayout {
+	layouts := []dateLayout{
+		{"2006-01-02T15:04:05Z07:00", precisionSecond, true},
+		{"2006-01-02 15:04:05Z07:00", precisionSecond, true},
+		{"2006-01-02T15:04:05", precisionSecond, false},
+		{"2006-01-02T15:04", precisionMinute, false},
+		// 兼容旧版本：0:00 视为只有日期
+		{"2006/1/2 0:00", precisionDay, false},
+	}
+	for _, sep := range []string{"-", "/", "."} {
+		date := "2006" + sep + "1" + sep + "2"
+		layouts = append(layouts,
+			dateLayout{date + " 15:04:05", precisionSecond, false},
+			dateLayout{date + " 15:04", precisionMinute, false},
+			dateLayout{date + " 15", precisionHour, false},
+			dateLayout{date, precisionDay, false},
+		)
+	}
+	// 年月和年（带"."的年月与小数无法区分，不识别）
+	layouts = append(layouts,
+		dateLayout{"2006-1", precisionMonth, false},
+		dateLayout{"2006/1", precisionMonth, false},
+		dateLayout{"2006/", precisionYear, false},
+	)
+	return layouts
+}
+
+// dayFirstLayouts 生成年份在末尾的纯数字日期格式，order为DMY或MDY
+func dayFirstLayouts(order string, separators []string) []dateLayout {
+	var layouts []dateLayout
+	for _, sep := range separators {
+		date := "2" + sep + "1" + sep + "2006"
+		if order == dateOrderMDY {
+			date = "1" + sep + "2" + sep + "2006"
+		}
+		layouts = append(layouts,
+			dateLayout{date + " 15:04:05", precisionSecond, false},
+			dateLayout{date + " 15:04", precisionMinute, false},
+			dateLayout{date, precisionDay, false},
+		)
+	}
+	return layouts
+}
+
+// ParseDateLayout 解析date_layout参数：格式名称（iso、date、datetime、rfc3339）或Go时间格式
+func ParseDateLayout(layout string) (string, error) {
+	if named, ok := namedDateLayouts[strings.ToLower(layout)]; ok {
+		return named, nil
+	}
+	// 格式中至少要包含一个时间元素
+	sample := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	if sample.Format(layout) == layout {
+		return "", errors.New("date_layout参数必须是iso、date、datetime、rfc3339或包含时间元素的Go时间格式（如 2006/01/02）")
+	}
+	return layout, nil
+}
+
+// ParseDateOrder 解析date_order参数
+func ParseDateOrder(order string) (string, error) {
+	switch strings.ToUpper(order) {
+	case "":
+		return "", nil
+	case dateOrderYMD, dateOrderDMY, dateOrderMDY:
+		return strings.ToUpper(order), nil
+	}
+	return "", errors.New("date_order参数必须是YMD、DMY或MDY")
+}
+
+// ParseTimezone 解析timezone参数：IANA时区名称（如 Asia/Shanghai）或UTC偏移（如 +08:00）
+func ParseTimezone(name string) (*time.Location, error) {
+	if t, err := time.Parse("-07:00", name); err == nil {
+		return t.Location(), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errors.New("无效的timezone参数: " + name)
+	}
+	return loc, nil
+}
+
+// recognizeDate 识别单元格文本中的日期，整个单元格都能解析为日期时才返回true
+func recognizeDate(value string, options model.DateOptions) (string, bool) {
+	date, ok := parseDateValue(value, options)
+	if !ok {
+		return "", false
+	}
+	return date.format(options), true
+}
+
+// parseDateValue 按优先级依次尝试各种日期格式
+func parseDateValue(value string, options model.DateOptions) (dateValue, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) < 4 || len(value) > maxDateLength || !containsDigit(value) {
+		return dateValue{}, false
+	}
+
+	loc := options.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	// ISO周日期
+	if match := isoWeekPattern.FindStringSubmatch(value); match != nil {
+		return parseISOWeek(match, loc)
+	}
+
+	// 中日韩年月日格式
+	if strings.ContainsAny(value, "年년") {
+		normalized := strings.Join(strings.Fields(cjkDateReplacer.Replace(value)), "")
+		return matchLayouts(normalized, cjkLayouts, loc)
+	}
+
+	// 英文月份名称
+	if strings.IndexFunc(value, unicode.IsLetter) >= 0 {
+		if date, ok := matchLayouts(value, monthNameLayouts, loc); ok {
+			return date, true
+		}
+		// 只有T和Z等字母的ISO格式继续按数字格式解析
+	}
+
+	if date, ok := matchLayouts(value, yearFirstLayouts, loc); ok {
+		return date, true
+	}
+
+	// 年份在末尾的纯数字日期
+	switch options.Order {
+	case dateOrderYMD:
+		return dateValue{}, false
+	case dateOrderDMY, dateOrderMDY:
+		return matchLayouts(value, dayFirstLayouts(options.Order, []string{".", "/", "-"}), loc)
+	}
+	// 未指定顺序时：点号和短横线分隔按日月年，斜杠分隔按月日年，无效时尝试另一种顺序
+	if date, ok := matchLayouts(value, dayFirstLayouts(dateOrderDMY, []string{".", "-"}), loc); ok {
+		return date, true
+	}
+	if date, ok := matchLayouts(value, dayFirstLayouts(dateOrderMDY, []string{"/"}), loc); ok {
+		return date, true
+	}
+	return matchLayouts(value, dayFirstLayouts(dateOrderDMY, []string{"/"}), loc)
+}
+
+// matchLayouts 返回第一个能完整解析文本的格式得到的日期
+func matchLayouts(value string, layouts []dateLayout, loc *time.Location) (dateValue, bool) {
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout.layout, value, loc); err == nil {
+			return dateValue{time: t, precision: layout.precision, zoned: layout.zoned}, true
+		}
+	}
+	return dateValue{}, false
+}
+
+// parseISOWeek 将ISO周日期转换为该周指定日（默认周一）的日期
+func parseISOWeek(match []string, loc *time.Location) (dateValue, bool) {
+	year, _ := strconv.Atoi(match[1])
+	week, _ := strconv.Atoi(match[2])
+	day := 1
+	if match[3] != "" {
+		day, _ = strconv.Atoi(match[3])
+	}
+	if week < 1 || week > 53 {
+		return dateValue{}, false
+	}
+
+	// 1月4日所在的周是第1周
+	jan4 := time.Date(year, 1, 4, 0, 0, 0, 0, loc)
+	weekday := int(jan4.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	t := jan4.AddDate(0, 0, (week-1)*7+day-weekday)
+	if y, w := t.ISOWeek(); y != year || w != week {
+		// 该年没有第53周
+		return dateValue{}, false
+	}
+	return dateValue{time: t, precision: precisionDay}, true
+}
+
+// format 按输出格式和时区格式化日期
+// 未指定输出格式时按识别到的精度输出ISO 8601格式，带时区偏移的日期时间输出为RFC 3339格式，
+// 只有时间的值始终输出为 15:04:05
+func (d dateValue) format(options model.DateOptions) string {
+	t := d.time
+	if d.zoned && options.Location != nil {
+		t = t.In(options.Location)
+	}
+	if options.Layout != "" && d.precision != precisionTime {
+		return t.Format(options.Layout)
+	}
+	if d.zoned {
+		return t.Format(time.RFC3339Nano)
+	}
+	return t.Format(precisionLayouts[d.precision])
+}
+
+// containsDigit 检查字符串中是否包含数字
+func containsDigit(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] >= '0' && value[i] <= '9' {
+			return true
+		}
+	}
+	return false
+}
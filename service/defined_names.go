@@ -0,0 +1,145 @@
+package service
+
+import (
+	"errors"
+	"file-url-parser/model"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// errDefinedNamesXLS .xls文件不支持表格和名称
+var errDefinedNamesXLS = errors.New("只有.xlsx文件支持表格（table_name）和名称（named_range）")
+
+// ListExcelTables 列出Excel文件中定义的表格（插入 > 表格）和名称
+func ListExcelTables(filePath string) ([]model.DefinedTableInfo, []model.DefinedNameInfo, error) {
+	f, err := openWorkbook(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	wb, ok := f.(*excelize.File)
+	if !ok {
+		return nil, nil, errDefinedNamesXLS
+	}
+
+	tables := []model.DefinedTableInfo{}
+	for _, sheetName := range wb.GetSheetList() {
+		// GetTables会加载整个工作表
+		sheetTables, err := wb.GetTables(sheetName)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, table := range sheetTables {
+			tables = append(tables, model.DefinedTableInfo{Name: table.Name, Sheet: sheetName, Range: table.Range})
+		}
+	}
+
+	names := []model.DefinedNameInfo{}
+	for _, definedName := range wb.GetDefinedName() {
+		info := model.DefinedNameInfo{
+			Name:     definedName.Name,
+			Scope:    definedName.Scope,
+			RefersTo: definedName.RefersTo,
+		}
+		// 只有引用单个区域的名称才能作为named_range解析
+		if sheetName, ref, err := splitRefersTo(definedName.RefersTo); err == nil {
+			info.Sheet, info.Range = sheetName, ref
+		}
+		names = append(names, info)
+	}
+	return tables, names, nil
+}
+
+// resolveDefinedRegion 根据table_name或named_range确定工作表和表格范围
+// 表格只有一行表头，未指定header_rows时按一行表头解析
+func resolveDefinedRegion(f workbook, options model.ParseOptions) (string, model.ParseOptions, error) {
+	wb, ok := f.(*excelize.File)
+	if !ok {
+		return "", options, errDefinedNamesXLS
+	}
+
+	var sheetName, ref string
+	if options.TableName != "" {
+		for _, name := range wb.GetSheetList() {
+			tables, err := wb.GetTables(name)
+			if err != nil {
+				return "", options, err
+			}
+			for _, table := range tables {
+				if strings.EqualFold(table.Name, options.TableName) {
+					sheetName, ref = name, table.Range
+					break
+				}
+			}
+			if ref != "" {
+				break
+			}
+		}
+		if ref == "" {
+			return "", options, errors.New("表格不存在: " + options.TableName)
+		}
+		if options.HeaderRows == 0 {
+			options.HeaderRows = 1
+		}
+	} else {
+		definedName, err := findDefinedName(wb, options)
+		if err != nil {
+			return "", options, err
+		}
+		if sheetName, ref, err = splitRefersTo(definedName.RefersTo); err != nil {
+			return "", options, errors.New("名称 " + definedName.Name + " " + err.Error() + ": " + definedName.RefersTo)
+		}
+		if _, err := wb.GetSheetIndex(sheetName); err != nil {
+			return "", options, err
+		}
+	}
+
+	tableRange, err := ParseCellRange(ref)
+	if err != nil {
+		return "", options, err
+	}
+	options.Table = tableRange
+	return sheetName, options, nil
+}
+
+// findDefinedName 查找named_range指定的名称（不区分大小写）
+// 同名的名称可以分别定义在工作簿和工作表范围内，指定了工作表时优先使用该工作表范围内的名称
+func findDefinedName(wb *excelize.File, options model.ParseOptions) (excelize.DefinedName, error) {
+	var found *excelize.DefinedName
+	for _, definedName := range wb.GetDefinedName() {
+		if !strings.EqualFold(definedName.Name, options.NamedRange) {
+			continue
+		}
+		if definedName.Scope == options.SheetName && options.SheetName != "" {
+			return definedName, nil
+		}
+		if found == nil || definedName.Scope == "Workbook" {
+			found = &definedName
+		}
+	}
+	if found == nil {
+		return excelize.DefinedName{}, errors.New("名称不存在: " + options.NamedRange)
+	}
+	return *found, nil
+}
+
+// splitRefersTo 将名称引用（如 "Sheet1!$A$1:$D$10"、"'销售 数据'!$B$4:$K$200"）拆分为工作表名称和区域
+func splitRefersTo(refersTo string) (string, string, error) {
+	refersTo = strings.TrimPrefix(strings.TrimSpace(refersTo), "=")
+	idx := strings.LastIndex(refersTo, "!")
+	if idx <= 0 || strings.ContainsAny(refersTo[idx+1:], ",() ") {
+		return "", "", errors.New("不是单个单元格区域的引用")
+	}
+
+	sheetName := refersTo[:idx]
+	if strings.HasPrefix(sheetName, "'") && strings.HasSuffix(sheetName, "'") && len(sheetName) >= 2 {
+		sheetName = strings.ReplaceAll(sheetName[1:len(sheetName)-1], "''", "'")
+	}
+	ref := strings.ReplaceAll(refersTo[idx+1:], "$", "")
+	if strings.Contains(sheetName, "!") || strings.Contains(ref, "#REF") {
+		return "", "", errors.New("不是单个单元格区域的引用")
+	}
+	return sheetName, ref, nil
+}
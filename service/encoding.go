@@ -0,0 +1,275 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	xunicode "golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// encodingSampleSize 检测字符编码时读取的样本大小
+const encodingSampleSize = 64 * 1024
+
+// 支持的字符编码名称
+const (
+	encodingUTF8        = "UTF-8"
+	encodingUTF16LE     = "UTF-16LE"
+	encodingUTF16BE     = "UTF-16BE"
+	encodingGBK         = "GBK"
+	encodingGB18030     = "GB18030"
+	encodingBig5        = "Big5"
+	encodingShiftJIS    = "Shift_JIS"
+	encodingLatin1      = "ISO-8859-1"
+	encodingWindows1252 = "windows-1252"
+)
+
+// encodingAliases 请求中可使用的编码名称（小写并去掉"-"和"_"后匹配）
+var encodingAliases = map[string]string{
+	"utf8":        encodingUTF8,
+	"utf16le":     encodingUTF16LE,
+	"utf16be":     encodingUTF16BE,
+	"gbk":         encodingGBK,
+	"gb2312":      encodingGBK,
+	"cp936":       encodingGBK,
+	"gb18030":     encodingGB18030,
+	"big5":        encodingBig5,
+	"shiftjis":    encodingShiftJIS,
+	"sjis":        encodingShiftJIS,
+	"cp932":       encodingShiftJIS,
+	"latin1":      encodingLatin1,
+	"iso88591":    encodingLatin1,
+	"windows1252": encodingWindows1252,
+	"cp1252":      encodingWindows1252,
+}
+
+// 用于区分简体中文、繁体中文和日文编码的常用字
+const (
+	commonSimplifiedChars  = "的一是不了人我在有他这中大来上国个到说们为子和你地出道也时年得就那要下以生会自着去之过家学对可她里后小么心多天而能好都然没日于起还发成事只作当想看文无开手十用主行方又如前所本见经头面公同三已老从动两长号码单价数量金额合计备注客户订单产品名称日期编类型状态"
+	commonTraditionalChars = "的一是不了人我在有他這中大來上國個到說們為子和你地出道也時年得就那要下以生會自著去之過家學對可她裡後小麼心多天而能好都然沒日於起還發成事只作當想看文無開手十用主行方又如前所本見經頭面公同三已老從動兩長號碼單價數量金額合計備註客戶訂單產品名稱日期編類型狀態"
+)
+
+// errUnsupportedEncoding 不支持的字符编码
+var errUnsupportedEncoding = errors.New("不支持的字符编码")
+
+// NormalizeEncodingName 将请求中的编码名称转换为标准名称
+func NormalizeEncodingName(name string) (string, error) {
+	key := strings.ToLower(name)
+	key = strings.NewReplacer("-", "", "_", "", " ", "").Replace(key)
+	if canonical, ok := encodingAliases[key]; ok {
+		return canonical, nil
+	}
+	return "", errors.New(errUnsupportedEncoding.Error() + ": " + name)
+}
+
+// lookupEncoding 根据标准名称获取编码，UTF-8编码会去掉开头的BOM
+func lookupEncoding(name string) encoding.Encoding {
+	switch name {
+	case encodingUTF16LE:
+		return xunicode.UTF16(xunicode.LittleEndian, xunicode.UseBOM)
+	case encodingUTF16BE:
+		return xunicode.UTF16(xunicode.BigEndian, xunicode.UseBOM)
+	case encodingGBK:
+		return simplifiedchinese.GBK
+	case encodingGB18030:
+		return simplifiedchinese.GB18030
+	case encodingBig5:
+		return traditionalchinese.Big5
+	case encodingShiftJIS:
+		return japanese.ShiftJIS
+	case encodingLatin1:
+		return charmap.ISO8859_1
+	case encodingWindows1252:
+		return charmap.Windows1252
+	default:
+		return xunicode.UTF8BOM
+	}
+}
+
+// newDecodedReader 读取样本检测字符编码（或使用指定的编码），返回转换为UTF-8的读取器和编码名称
+func newDecodedReader(reader io.Reader, encodingName string) (io.Reader, string, error) {
+	bufReader := bufio.NewReaderSize(reader, encodingSampleSize)
+	if encodingName == "" {
+		sample, err := bufReader.Peek(encodingSampleSize)
+		if err != nil && err != io.EOF {
+			return nil, "", err
+		}
+		encodingName = detectEncoding(sample, err == nil)
+	}
+
+	decoder := lookupEncoding(encodingName).NewDecoder()
+	return transform.NewReader(bufReader, decoder), encodingName, nil
+}
+
+// decodeBytes 检测字符编码（或使用指定的编码）并将内容转换为UTF-8
+func decodeBytes(data []byte, encodingName string) (string, string, error) {
+	if encodingName == "" {
+		sample := data
+		if len(sample) > encodingSampleSize {
+			sample = sample[:encodingSampleSize]
+		}
+		encodingName = detectEncoding(sample, len(data) > len(sample))
+	}
+
+	decoded, _, err := transform.Bytes(lookupEncoding(encodingName).NewDecoder(), data)
+	if err != nil {
+		return "", encodingName, err
+	}
+	return string(decoded), encodingName, nil
+}
+
+// detectEncoding 根据样本检测字符编码
+// 依次检查BOM、UTF-16特征、UTF-8有效性，最后按常用字命中情况在GBK/GB18030、Big5、Shift_JIS中选择，
+// 都无法解码或没有命中常用字时视为Latin-1
+func detectEncoding(sample []byte, truncated bool) string {
+	// 检查BOM
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		return encodingUTF8
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return encodingUTF16LE
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return encodingUTF16BE
+	}
+
+	// 没有BOM的UTF-16：ASCII字符的高字节为0
+	if name := detectUTF16(sample); name != "" {
+		return name
+	}
+
+	if isValidUTF8(sample, truncated) {
+		return encodingUTF8
+	}
+
+	// 按常用字命中数选择中日文编码，至少命中一个常用字或假名，
+	// 否则西欧文本（如Latin-1的ö、ü）也能按GB18030解码为生僻字
+	best, bestScore := "", 0
+	for _, candidate := range []string{encodingGB18030, encodingBig5, encodingShiftJIS} {
+		decoded, _, err := transform.Bytes(lookupEncoding(candidate).NewDecoder(), sample)
+		if err != nil {
+			continue
+		}
+		text := string(decoded)
+		if truncated {
+			// 样本末尾可能截断了多字节字符
+			text = strings.TrimSuffix(text, string(utf8.RuneError))
+		}
+		if strings.ContainsRune(text, utf8.RuneError) {
+			continue
+		}
+		if score := scoreDecodedText(text, candidate); score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+
+	switch best {
+	case "":
+		// 无法按中日文编码解码或没有命中常用字，视为西欧编码
+		for _, b := range sample {
+			if b >= 0x80 && b <= 0x9F {
+				return encodingWindows1252
+			}
+		}
+		return encodingLatin1
+	case encodingGB18030:
+		// 没有四字节序列时按GBK报告
+		if !hasGB18030FourByteSequence(sample) {
+			return encodingGBK
+		}
+	}
+	return best
+}
+
+// detectUTF16 根据零字节的分布检测没有BOM的UTF-16文本
+func detectUTF16(sample []byte) string {
+	if len(sample) > 4096 {
+		sample = sample[:4096]
+	}
+	if len(sample) < 4 {
+		return ""
+	}
+
+	evenZeros, oddZeros := 0, 0
+	for i, b := range sample {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+
+	half := len(sample) / 2
+	switch {
+	case oddZeros*10 > half*3 && evenZeros*20 < half:
+		return encodingUTF16LE
+	case evenZeros*10 > half*3 && oddZeros*20 < half:
+		return encodingUTF16BE
+	}
+	return ""
+}
+
+// isValidUTF8 检查样本是否为有效的UTF-8，样本被截断时允许末尾存在不完整的字符
+func isValidUTF8(sample []byte, truncated bool) bool {
+	if utf8.Valid(sample) {
+		return true
+	}
+	if !truncated {
+		return false
+	}
+	for i := 1; i < utf8.UTFMax && i <= len(sample); i++ {
+		if utf8.Valid(sample[:len(sample)-i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// scoreDecodedText 计算按指定编码解码后的文本得分：常用字和日文假名的命中数
+func scoreDecodedText(text string, encodingName string) int {
+	score := 0
+	for _, r := range text {
+		switch encodingName {
+		case encodingGB18030:
+			if strings.ContainsRune(commonSimplifiedChars, r) {
+				score++
+			}
+		case encodingBig5:
+			if strings.ContainsRune(commonTraditionalChars, r) {
+				score++
+			}
+		case encodingShiftJIS:
+			if unicode.In(r, unicode.Hiragana, unicode.Katakana) {
+				score++
+			}
+		}
+	}
+	return score
+}
+
+// hasGB18030FourByteSequence 检查是否存在GB18030特有的四字节编码
+func hasGB18030FourByteSequence(sample []byte) bool {
+	for i := 0; i+3 < len(sample); i++ {
+		if sample[i] < 0x81 {
+			continue
+		}
+		if sample[i+1] >= 0x30 && sample[i+1] <= 0x39 && sample[i+2] >= 0x81 && sample[i+3] >= 0x30 && sample[i+3] <= 0x39 {
+			return true
+		}
+		// 跳过双字节字符的尾字节
+		i++
+	}
+	return false
+}
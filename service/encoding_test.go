@@ -0,0 +1,108 @@
+package service
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	xunicode "golang.org/x/text/encoding/unicode"
+)
+
+// encodeText 将UTF-8文本按指定编码转换为字节，用于构造测试样本
+func encodeText(t *testing.T, enc encoding.Encoding, text string) []byte {
+	t.Helper()
+	data, err := enc.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// TestDetectEncoding 按BOM和内容检测常见编码，西欧文本不会被识别为中日文编码
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		want     string
+		wantText string
+	}{
+		{
+			name:     "UTF-8",
+			data:     []byte("名称,数量\n苹果,3\n"),
+			want:     encodingUTF8,
+			wantText: "名称,数量\n苹果,3\n",
+		},
+		{
+			name:     "UTF-8 BOM",
+			data:     append([]byte{0xEF, 0xBB, 0xBF}, "名称,数量\n苹果,3\n"...),
+			want:     encodingUTF8,
+			wantText: "名称,数量\n苹果,3\n",
+		},
+		{
+			name:     "UTF-16LE BOM",
+			data:     encodeText(t, xunicode.UTF16(xunicode.LittleEndian, xunicode.UseBOM), "名称,数量\n苹果,3\n"),
+			want:     encodingUTF16LE,
+			wantText: "名称,数量\n苹果,3\n",
+		},
+		{
+			name:     "UTF-16BE BOM",
+			data:     encodeText(t, xunicode.UTF16(xunicode.BigEndian, xunicode.UseBOM), "名称,数量\n苹果,3\n"),
+			want:     encodingUTF16BE,
+			wantText: "名称,数量\n苹果,3\n",
+		},
+		{
+			name:     "UTF-16LE 无BOM",
+			data:     encodeText(t, xunicode.UTF16(xunicode.LittleEndian, xunicode.IgnoreBOM), "name,qty\napple,3\n"),
+			want:     encodingUTF16LE,
+			wantText: "name,qty\napple,3\n",
+		},
+		{
+			name:     "GBK",
+			data:     encodeText(t, simplifiedchinese.GBK, "名称,数量\n苹果,3\n香蕉,5\n"),
+			want:     encodingGBK,
+			wantText: "名称,数量\n苹果,3\n香蕉,5\n",
+		},
+		{
+			name:     "Big5",
+			data:     encodeText(t, traditionalchinese.Big5, "名稱,數量\n產品,3\n訂單,5\n"),
+			want:     encodingBig5,
+			wantText: "名稱,數量\n產品,3\n訂單,5\n",
+		},
+		{
+			name:     "Shift_JIS",
+			data:     encodeText(t, japanese.ShiftJIS, "名前,数量\nりんご,3\nバナナ,5\n"),
+			want:     encodingShiftJIS,
+			wantText: "名前,数量\nりんご,3\nバナナ,5\n",
+		},
+		{
+			name:     "Latin-1",
+			data:     encodeText(t, charmap.ISO8859_1, "name;note\nBjörn;über\n"),
+			want:     encodingLatin1,
+			wantText: "name;note\nBjörn;über\n",
+		},
+		{
+			name:     "Windows-1252",
+			data:     encodeText(t, charmap.Windows1252, "name;price\nCafé;€5\n"),
+			want:     encodingWindows1252,
+			wantText: "name;price\nCafé;€5\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, name, err := decodeBytes(tt.data, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != tt.want {
+				t.Errorf("检测到的编码为 %s，应为 %s", name, tt.want)
+			}
+			if text != tt.wantText {
+				t.Errorf("解码结果为 %q，应为 %q", text, tt.wantText)
+			}
+		})
+	}
+}
@@ -2,221 +2,534 @@ package service
 
 import (
 	"errors"
-	"file-url-parser/config"
-	"file-url-parser/utils"
+	"file-url-parser/model"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/xuri/excelize/v2"
 )
 
 // ExcelParseResult Excel解析结果
 type ExcelParseResult struct {
-	Data           []map[string]interface{} // 解析后的数据
-	Headers        []string                 // 使用的表头（可能是原始表头或统一格式）
-	OriginalHeaders []string                // 原始表头
+	Data            []map[string]interface{}        // 解析后的数据
+	Headers         []string                        // 使用的表头（可能是原始表头或统一格式）
+	OriginalHeaders []string                        // 原始表头
+	Encoding        string                          // 文本文件（CSV）的字符编码
+	HeaderTree      []model.HeaderNode              // 多行表头的层级结构，只有一行表头时为nil
+	TableOrigin     *model.TableOrigin              // 表格的起始位置
+	Range           string                          // 表格区域（A1格式），只在tables=all时设置
+	Tables          []ExcelParseResult              // tables=all时工作表中的各个表格
+	Metadata        []map[string]model.CellMetadata // 与Data逐行对应的单元格附加信息，未开启cell_metadata时为nil
+	SkippedRows     []int                           // 跳过的隐藏行（行号从1开始）
+	SkippedColumns  []string                        // 跳过的隐藏列的列名
 }
 
-// ParseExcel 解析Excel文件
-func ParseExcel(filePath string, offset int, limit int) (ExcelParseResult, error) {
+// workbook 工作簿的统一访问接口，屏蔽.xlsx（excelize）与.xls（BIFF8）的格式差异
+type workbook interface {
+	GetSheetList() []string
+	GetSheetVisible(sheet string) (bool, error)
+	GetSheetDimension(sheet string) (string, error)
+	Close() error
+}
+
+// openWorkbook 根据文件内容打开工作簿，OLE2复合文档按.xls格式解析，其他按OOXML格式解析
+func openWorkbook(filePath string) (workbook, error) {
+	if isXLSFile(filePath) {
+		return openXLSWorkbook(filePath)
+	}
+	return excelize.OpenFile(filePath)
+}
+
+// ParseExcel 解析Excel文件中指定的工作表（默认第一个工作表）
+func ParseExcel(filePath string, options model.ParseOptions) (ExcelParseResult, error) {
 	// 打开Excel文件
-	f, err := excelize.OpenFile(filePath)
+	f, err := openWorkbook(filePath)
 	if err != nil {
 		return ExcelParseResult{}, err
 	}
 	defer f.Close()
 
-	// 获取第一个工作表
-	sheetName := f.GetSheetList()[0]
+	// 按表格或名称解析时，使用其所在的工作表和区域
+	if options.TableName != "" || options.NamedRange != "" {
+		sheetName, regionOptions, err := resolveDefinedRegion(f, options)
+		if err != nil {
+			return ExcelParseResult{}, err
+		}
+		if err := checkSheetVisible(f, sheetName, options); err != nil {
+			return ExcelParseResult{}, err
+		}
+		return parseExcelSheet(f, sheetName, regionOptions)
+	}
 
-	// 获取所有单元格
-	rows, err := f.GetRows(sheetName)
+	// 确定要解析的工作表
+	sheetName, err := resolveSheetName(f, options)
 	if err != nil {
 		return ExcelParseResult{}, err
 	}
 
-	// 检查是否有数据
-	if len(rows) == 0 {
-		// 空文件
-		return ExcelParseResult{Data: []map[string]interface{}{}}, nil
-	}
-
-	// 查找表格数据的实际起始位置
-	startRow, startCol, headerRow := findTableStart(rows)
-	if startRow == -1 || headerRow == nil {
-		// 找不到有效的表格数据
-		return ExcelParseResult{Data: []map[string]interface{}{}}, nil
-	}
-
-	// 获取最大允许行数
-	maxAllowedRows := config.GetMaxAllowedRows()
-	
-	// 检查是否有数据行
-	totalDataRows := len(rows) - (startRow + 1)
-	if totalDataRows <= 0 {
-		// 没有数据行，只有表头
-		return ExcelParseResult{
-			Data:           []map[string]interface{}{},
-			Headers:        []string{},
-			OriginalHeaders: []string{},
-		}, nil
-	}
-	
-	// 处理无限制的情况 (maxAllowedRows = -1)
-	hasRowLimit := maxAllowedRows != -1
-	
-	// 如果有行数限制且数据量超过限制
-	if hasRowLimit && totalDataRows > maxAllowedRows {
-		return ExcelParseResult{}, errors.New("数据行数超过限制，最多允许 " + strconv.Itoa(maxAllowedRows) + " 行数据")
-	}
-
-	// 使用找到的表头行
-	originalHeaders := headerRow[startCol:]
-	
-	// 根据配置决定使用哪种键
-	useHeaderAsKey := config.GetUseHeaderAsKey()
-	var headers []string
-	
-	if useHeaderAsKey {
-		// 使用原始表头
-		headers = originalHeaders
-	} else {
-		// 使用统一格式的表头 Col_1, Col_2, ...，保持原始顺序
-		headers = make([]string, len(originalHeaders))
-		for i := range originalHeaders {
-			// 使用1-based索引，与Excel列号保持一致
-			headers[i] = fmt.Sprintf("Col_%d", i+1)
-		}
-	}
-
-	// 处理分页参数
-	// 计算实际的行索引范围
-	startIndex := startRow + 1 + offset // 从表头下一行开始计算偏移
-	endIndex := len(rows)
-	
-	// 如果指定了limit并且大于0，计算结束索引
-	if limit > 0 {
-		endIndex = startIndex + limit
-		if endIndex > len(rows) {
-			endIndex = len(rows)
-		}
-	}
-	
-	// 检查起始索引是否已经超出数据范围
-	if startIndex >= len(rows) {
-		// 偏移量超出范围，返回空数据
-		return ExcelParseResult{
-			Data:           []map[string]interface{}{},
-			Headers:        headers,
-			OriginalHeaders: originalHeaders,
-		}, nil
-	}
-
-	// 解析数据，只处理指定范围内的行
-	var result []map[string]interface{}
-	for i := startIndex; i < endIndex; i++ {
-		row := rows[i]
-		
-		// 跳过空行
-		if len(row) <= startCol || isEmptyRow(row, startCol) {
+	return parseExcelSheet(f, sheetName, options)
+}
+
+// AllSheetsResult 解析所有工作表的结果
+type AllSheetsResult struct {
+	SheetNames   []string                    // 解析的工作表名称（按工作簿顺序，包括解析失败的工作表）
+	Results      map[string]ExcelParseResult // 解析成功的工作表的结果
+	SheetErrors  map[string]error            // 解析失败的工作表的错误
+	HiddenSheets []string                    // 不包括隐藏工作表时跳过的工作表
+}
+
+// ParseExcelAllSheets 解析Excel文件中的所有工作表
+// 某个工作表解析失败时记录该工作表的错误并继续解析其他工作表，所有工作表都失败时返回第一个错误
+func ParseExcelAllSheets(filePath string, options model.ParseOptions) (AllSheetsResult, error) {
+	// 打开Excel文件
+	f, err := openWorkbook(filePath)
+	if err != nil {
+		return AllSheetsResult{}, err
+	}
+	defer f.Close()
+
+	sheetNames, hiddenSheets, err := visibleSheetList(f, options)
+	if err != nil {
+		return AllSheetsResult{}, err
+	}
+	all := AllSheetsResult{
+		SheetNames:   sheetNames,
+		Results:      make(map[string]ExcelParseResult, len(sheetNames)),
+		SheetErrors:  make(map[string]error),
+		HiddenSheets: hiddenSheets,
+	}
+	var firstErr error
+	for _, sheetName := range sheetNames {
+		result, err := parseExcelSheet(f, sheetName, options)
+		if err != nil {
+			all.SheetErrors[sheetName] = err
+			if firstErr == nil {
+				firstErr = fmt.Errorf("工作表 %s: %w", sheetName, err)
+			}
 			continue
 		}
-		
-		item := make(map[string]interface{})
-		rowData := row[startCol:] // 只处理从起始列开始的数据
-
-		// 确保行数据与表头匹配
-		for j := 0; j < len(headers) && j < len(rowData); j++ {
-			cellValue := rowData[j]
-			
-			// 跳过空单元格
-			if cellValue == "" {
-				continue
-			}
+		all.Results[sheetName] = result
+	}
+	if len(all.Results) == 0 && firstErr != nil {
+		return AllSheetsResult{}, firstErr
+	}
+
+	return all, nil
+}
+
+// ListExcelSheets 列出Excel文件中的工作表信息，不解析行数据
+func ListExcelSheets(filePath string) ([]model.SheetInfo, error) {
+	// 打开Excel文件
+	f, err := openWorkbook(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheets := make([]model.SheetInfo, 0, len(f.GetSheetList()))
+	for index, sheetName := range f.GetSheetList() {
+		visible, err := f.GetSheetVisible(sheetName)
+		if err != nil {
+			return nil, err
+		}
+		dimension, err := f.GetSheetDimension(sheetName)
+		if err != nil {
+			return nil, err
+		}
+
+		info := model.SheetInfo{
+			Name:      sheetName,
+			Index:     index,
+			Visible:   visible,
+			Dimension: dimension,
+		}
+		info.Rows, info.Cols = dimensionSize(dimension)
+		sheets = append(sheets, info)
+	}
 
-			// 尝试解析数值
-			if val, err := strconv.ParseFloat(cellValue, 64); err == nil {
-				item[headers[j]] = val
-				continue
+	return sheets, nil
+}
+
+// resolveSheetName 根据解析选项确定要解析的工作表名称
+// 不包括隐藏工作表时，索引只计算可见的工作表，按名称指定隐藏工作表时返回错误
+func resolveSheetName(f workbook, options model.ParseOptions) (string, error) {
+	// 按名称查找
+	if options.SheetName != "" {
+		sheetName, err := findSheetName(f.GetSheetList(), options.SheetName)
+		if err != nil {
+			return "", err
+		}
+		if err := checkSheetVisible(f, sheetName, options); err != nil {
+			return "", err
+		}
+		return sheetName, nil
+	}
+
+	sheetNames, _, err := visibleSheetList(f, options)
+	if err != nil {
+		return "", err
+	}
+	if len(sheetNames) == 0 {
+		return "", errors.New("Excel文件中没有工作表")
+	}
+
+	// 按索引查找
+	if options.SheetIndex < 0 || options.SheetIndex >= len(sheetNames) {
+		return "", fmt.Errorf("工作表索引超出范围，共有 %d 个工作表", len(sheetNames))
+	}
+	return sheetNames[options.SheetIndex], nil
+}
+
+// findSheetName 按名称查找工作表，先精确匹配，再不区分大小写匹配
+func findSheetName(sheetNames []string, name string) (string, error) {
+	for _, sheetName := range sheetNames {
+		if sheetName == name {
+			return sheetName, nil
+		}
+	}
+	for _, sheetName := range sheetNames {
+		if strings.EqualFold(sheetName, name) {
+			return sheetName, nil
+		}
+	}
+	return "", errors.New("工作表不存在: " + name)
+}
+
+// dimensionSize 根据数据范围（如 A1:K200）计算行数和列数
+func dimensionSize(dimension string) (int, int) {
+	if dimension == "" {
+		return 0, 0
+	}
+
+	cells := strings.Split(dimension, ":")
+	startCol, startRow, err := excelize.CellNameToCoordinates(cells[0])
+	if err != nil {
+		return 0, 0
+	}
+	endCol, endRow := startCol, startRow
+	if len(cells) > 1 {
+		if endCol, endRow, err = excelize.CellNameToCoordinates(cells[1]); err != nil {
+			return 0, 0
+		}
+	}
+
+	return endRow - startRow + 1, endCol - startCol + 1
+}
+
+// dimensionLastCol 返回数据范围（如 A1:K200）的最后一列的列号，没有数据范围时返回0
+func dimensionLastCol(dimension string) int {
+	start, end, _ := strings.Cut(dimension, ":")
+	if end == "" {
+		end = start
+	}
+	col, _, err := excelize.CellNameToCoordinates(end)
+	if err != nil {
+		return 0
+	}
+	return col
+}
+
+// parseExcelSheet 解析单个工作表
+// .xlsx使用excelize的流式迭代器逐行读取，偏移量之前的行不解析单元格，读取完分页数据后即停止
+func parseExcelSheet(f workbook, sheetName string, options model.ParseOptions) (ExcelParseResult, error) {
+	switch wb := f.(type) {
+	case *xlsWorkbook:
+		// .xls文件最多65536行，直接读取整个工作表
+		rows, cells, err := wb.GetRows(sheetName)
+		if err != nil {
+			return ExcelParseResult{}, err
+		}
+		if options.Formulas != "" && options.Formulas != model.FormulasCached {
+			return ExcelParseResult{}, errFormulasXLS
+		}
+		if options.CellMetadata != "" && options.CellMetadata != model.CellMetadataNone {
+			return ExcelParseResult{}, errCellMetadataXLS
+		}
+		if options.MergedCells != "" && options.MergedCells != model.MergedCellsNone {
+			merges, err := wb.GetMergeCells(sheetName)
+			if err != nil {
+				return ExcelParseResult{}, err
 			}
+			fillMergedRows(rows, cells, merges, options.MergedCells)
+		}
 
-			// 特殊处理日期格式
-			if isLikelyDate(cellValue) {
-				// 尝试解析为标准格式日期
-				if formattedDate, ok := formatDateString(cellValue); ok {
-					item[headers[j]] = formattedDate
-					continue
+		var rowHidden, colHidden func(int) (bool, error)
+		if !options.IncludeHidden.Rows || !options.IncludeHidden.Columns {
+			hiddenRows, hiddenCols, err := wb.GetHiddenRowsCols(sheetName)
+			if err != nil {
+				return ExcelParseResult{}, err
+			}
+			if !options.IncludeHidden.Rows {
+				rowHidden = func(row int) (bool, error) {
+					return hiddenRows[row-1], nil
 				}
 			}
+			if !options.IncludeHidden.Columns {
+				colHidden = func(col int) (bool, error) {
+					return hiddenCols[col], nil
+				}
+			}
+		}
+		reader := &typedSliceRowReader{sliceRowReader: sliceRowReader{rows: rows}, cells: cells}
+		return parseVisibleTables(reader, rowHidden, colHidden, options)
+	case *excelize.File:
+		rows, err := wb.Rows(sheetName)
+		if err != nil {
+			return ExcelParseResult{}, err
+		}
+		defer rows.Close()
+
+		reader := &excelRowReader{rows: rows}
+		if options.CellValues != model.CellValuesFormatted {
+			// 同时读取单元格的原始值和样式ID，用于按单元格类型转换
+			cellXML, err := openSheetCellReader(wb.Path, sheetName)
+			if err != nil {
+				return ExcelParseResult{}, err
+			}
+			defer cellXML.Close()
 
-			// 处理逗号分隔的内容
-			if utils.IsCommaList(cellValue) {
-				item[headers[j]] = utils.ProcessCommaList(cellValue)
-				continue
+			props, err := wb.GetWorkbookProps()
+			if err != nil {
+				return ExcelParseResult{}, err
+			}
+			reader.file = wb
+			reader.cellXML = cellXML
+			reader.date1904 = props.Date1904 != nil && *props.Date1904
+			reader.formats = make(map[int]numberFormatKind)
+			reader.cells = make(map[int][]typedCell)
+		}
+		if options.MergedCells != "" && options.MergedCells != model.MergedCellsNone {
+			// 读取合并单元格区域需要加载整个工作表
+			merges, err := excelizeMergeRanges(wb, sheetName, reader.cellXML != nil)
+			if err != nil {
+				return ExcelParseResult{}, err
+			}
+			reader.merged = &mergedCellFiller{mode: options.MergedCells, merges: merges}
+		}
+		if options.Formulas != "" && options.Formulas != model.FormulasCached {
+			if reader.formulas, err = newFormulaReader(wb, sheetName, options.Formulas); err != nil {
+				return ExcelParseResult{}, err
 			}
+		}
+		if options.CellMetadata != "" && options.CellMetadata != model.CellMetadataNone {
+			if reader.metadata, err = newCellMetadataReader(wb, sheetName, options.CellMetadata); err != nil {
+				return ExcelParseResult{}, err
+			}
+		}
+		if reader.cells == nil && (reader.formulas != nil || reader.metadata != nil) {
+			reader.cells = make(map[int][]typedCell)
+		}
 
-			// 默认为字符串
-			item[headers[j]] = cellValue
+		// 读取行列的可见性需要加载整个工作表
+		var rowHidden, colHidden func(int) (bool, error)
+		if !options.IncludeHidden.Rows {
+			rowHidden = func(row int) (bool, error) {
+				visible, err := wb.GetRowVisible(sheetName, row)
+				return !visible, err
+			}
 		}
+		if !options.IncludeHidden.Columns {
+			colHidden = func(col int) (bool, error) {
+				name, err := excelize.ColumnNumberToName(col + 1)
+				if err != nil {
+					return false, err
+				}
+				visible, err := wb.GetColVisible(sheetName, name)
+				return !visible, err
+			}
+		}
+		return parseVisibleTables(reader, rowHidden, colHidden, options)
+	default:
+		return ExcelParseResult{}, errors.New("不支持的工作簿格式")
+	}
+}
 
-		// 只添加非空的数据项
-		if len(item) > 0 {
-			result = append(result, item)
+// excelRowReader 基于excelize流式迭代器的行读取器
+// cellXML不为nil时同时读取单元格的原始值，并根据单元格样式的数字格式得到类型化的值
+type excelRowReader struct {
+	rows *excelize.Rows
+
+	file     *excelize.File
+	cellXML  *sheetCellReader         // 读取单元格原始值和样式ID的读取器，与rows按行号同步
+	date1904 bool                     // 工作簿是否使用1904日期系统
+	rowNum   int                      // 已读取或跳过的行数
+	formats  map[int]numberFormatKind // 样式ID -> 数字格式类别
+	cells    map[int][]typedCell      // 行号 -> 尚未取出的类型化单元格值
+
+	merged   *mergedCellFiller   // 合并单元格填充，nil表示不填充
+	formulas *formulaReader      // 公式读取，nil表示输出缓存的计算结果
+	metadata *cellMetadataReader // 单元格附加信息读取，nil表示不输出
+}
+
+// Next 读取下一行的单元格内容
+func (r *excelRowReader) Next() ([]string, error) {
+	if err := r.advance(); err != nil {
+		return nil, err
+	}
+	row, err := r.rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var raw []string
+	var styles []int
+	if r.cellXML != nil {
+		if raw, styles, err = r.cellXML.read(r.rowNum, row); err != nil {
+			return nil, err
 		}
+		if r.merged != nil {
+			raw = r.merged.fillRow(raw, r.rowNum-1, true)
+			styles = r.merged.fillStyles(styles, r.rowNum-1)
+		}
+	}
+	if r.merged != nil {
+		row = r.merged.fillRow(row, r.rowNum-1, false)
 	}
 
-	// 对结果中的所有字段再次检查是否有逗号分隔的内容
-	for i := range result {
-		for key, value := range result[i] {
-			if strValue, ok := value.(string); ok {
-				if utils.IsCommaList(strValue) {
-					result[i][key] = utils.ProcessCommaList(strValue)
+	var formulas map[int]string
+	if r.formulas != nil {
+		if formulas, err = r.formulas.read(r.rowNum-1, len(row)); err != nil {
+			return nil, err
+		}
+		switch r.formulas.mode {
+		case model.FormulasCalculate:
+			row, raw = r.formulas.calculate(r.rowNum-1, row, raw, formulas)
+			formulas = nil
+		case model.FormulasText:
+			// 公式单元格的内容为公式文本（与Excel中显示公式时相同）
+			for col, formula := range formulas {
+				row = setCellText(row, col, formula)
+				if raw != nil {
+					raw = setCellText(raw, col, formula)
 				}
 			}
 		}
 	}
 
-	parseResult := ExcelParseResult{
-		Data:           result,
-		Headers:        headers,
-		OriginalHeaders: originalHeaders,
+	if r.cellXML == nil && formulas == nil && r.metadata == nil {
+		return row, nil
+	}
+	var cells []typedCell
+	if r.cellXML != nil && hasAnyValue(row) {
+		cells = r.typedCells(row, raw, styles)
+	}
+	for col, formula := range formulas {
+		for len(cells) <= col {
+			cells = append(cells, typedCell{})
+		}
+		cells[col].formula = formula
+	}
+	if r.metadata != nil {
+		if cells, err = r.metadata.read(r.rowNum-1, row, cells); err != nil {
+			return nil, err
+		}
 	}
+	if cells != nil {
+		r.cells[r.rowNum-1] = cells
+	}
+	return row, nil
+}
 
-	return parseResult, nil
+// Skip 跳过下一行，不解析单元格内容
+func (r *excelRowReader) Skip() error {
+	return r.advance()
 }
 
-// findTableStart 查找表格数据的实际起始位置
-// 返回表头行索引、起始列索引和表头行内容
-func findTableStart(rows [][]string) (int, int, []string) {
-	// 至少需要两行数据（表头+数据）
-	if len(rows) < 2 {
-		return -1, -1, nil
-	}
-	
-	// 查找第一个非空单元格，这可能是表头的起始位置
-	for rowIdx, row := range rows {
-		for colIdx, cell := range row {
-			if strings.TrimSpace(cell) != "" {
-				// 找到第一个非空单元格，检查下一行是否也有内容（表示这是表头行）
-				if rowIdx+1 < len(rows) && len(rows[rowIdx+1]) > colIdx && strings.TrimSpace(rows[rowIdx+1][colIdx]) != "" {
-					// 验证这是否是一个有效的表头行（检查是否有足够的连续非空单元格）
-					headerCount := countConsecutiveNonEmptyCells(row, colIdx)
-					if headerCount >= 1 {
-						return rowIdx, colIdx, row
-					}
-				}
+// advance 将迭代器移动到下一行
+func (r *excelRowReader) advance() error {
+	if !r.rows.Next() {
+		if err := r.rows.Error(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	r.rowNum++
+	if r.merged != nil {
+		r.merged.advance(r.rowNum - 1)
+	}
+	return nil
+}
+
+// CellValues 返回指定行的类型化单元格值，同时释放该行及之前各行的缓存
+func (r *excelRowReader) CellValues(rowNum int) []typedCell {
+	cells := r.cells[rowNum]
+	for n := range r.cells {
+		if n <= rowNum {
+			delete(r.cells, n)
+		}
+	}
+	return cells
+}
+
+// typedCells 根据显示文本、原始值和样式ID得到一行单元格的类型化值
+func (r *excelRowReader) typedCells(row []string, raw []string, styles []int) []typedCell {
+	cells := make([]typedCell, len(row))
+	for col, text := range row {
+		if text == "" || col >= len(raw) {
+			continue
+		}
+		cells[col].raw = raw[col]
+		// 显示文本与原始值相同时（文本或常规格式的数值）按显示文本推断类型
+		if text == raw[col] {
+			continue
+		}
+
+		// 布尔值单元格显示为TRUE/FALSE，原始值为1/0
+		if (text == "TRUE" && raw[col] == "1") || (text == "FALSE" && raw[col] == "0") {
+			cells[col].value = raw[col] == "1"
+			continue
+		}
+
+		value, err := strconv.ParseFloat(raw[col], 64)
+		if err != nil {
+			continue
+		}
+		styleID := 0
+		if col < len(styles) {
+			styleID = styles[col]
+		}
+		switch kind := r.numberFormatKind(styleID); kind {
+		case numberFormatDate, numberFormatTime, numberFormatDateTime:
+			if date, ok := serialDate(value, kind, r.date1904); ok {
+				cells[col].value = date
 			}
+		default:
+			cells[col].value = excelNumber(formatGeneralNumber(value))
 		}
 	}
-	
-	// 如果没有找到符合条件的表头行，使用第一行作为表头（如果有数据）
-	if len(rows) > 0 && len(rows[0]) > 0 {
-		return 0, 0, rows[0]
+	return cells
+}
+
+// numberFormatKind 获取样式的数字格式类别，每个样式ID只查找一次样式表
+func (r *excelRowReader) numberFormatKind(styleID int) numberFormatKind {
+	if kind, ok := r.formats[styleID]; ok {
+		return kind
 	}
-	
-	return -1, -1, nil
+	kind := classifyNumberFormat(styleNumberFormat(r.file, styleID))
+	r.formats[styleID] = kind
+	return kind
+}
+
+// styleNumberFormat 获取样式的数字格式代码
+// 自定义格式按格式ID在样式表中查找，内置格式使用builtInNumberFormats
+func styleNumberFormat(f *excelize.File, styleID int) string {
+	// GetStyle会加载样式表
+	if _, err := f.GetStyle(styleID); err != nil || f.Styles == nil || f.Styles.CellXfs == nil {
+		return ""
+	}
+	xf := f.Styles.CellXfs.Xf[styleID]
+	if xf.NumFmtID == nil {
+		return ""
+	}
+	if f.Styles.NumFmts != nil {
+		for _, numFmt := range f.Styles.NumFmts.NumFmt {
+			if numFmt.NumFmtID == *xf.NumFmtID {
+				return numFmt.FormatCode
+			}
+		}
+	}
+	return builtInNumberFormats[*xf.NumFmtID]
 }
 
 // countConsecutiveNonEmptyCells 计算从指定位置开始的连续非空单元格数量
@@ -252,110 +565,3 @@ func isEmptyRow(row []string, startCol int) bool {
 	}
 	return true
 }
-
-// isLikelyDate 检查字符串是否可能是日期
-func isLikelyDate(value string) bool {
-	value = strings.TrimSpace(value)
-	if value == "" {
-		return false
-	}
-
-	// 检查是否包含日期分隔符
-	if strings.Contains(value, "-") || strings.Contains(value, "/") || strings.Contains(value, ".") {
-		return true
-	}
-
-	// 检查是否包含年份标记
-	if strings.Contains(value, "年") {
-		return true
-	}
-
-	return false
-}
-
-// formatDateString 格式化日期字符串
-func formatDateString(value string) (string, bool) {
-	value = strings.TrimSpace(value)
-
-	// 首先检查是否为标准格式（带有"-"的格式）
-	// 如果已经是标准格式，直接返回原值
-	if strings.Contains(value, "-") {
-		return value, true
-	}
-
-	// 只处理带有"/"的日期格式
-	if !strings.Contains(value, "/") {
-		// 非"/"分隔的日期格式，保持原样
-		return value, true
-	}
-
-	// 处理带有"/"的日期格式
-	slashFormats := []string{
-		// 年月日时分秒格式
-		"2006/1/2 15:04:05",
-		"2006/01/02 15:04:05",
-		
-		// 年月日时分格式
-		"2006/1/2 15:04",
-		"2006/01/02 15:04",
-		
-		// 年月日时格式
-		"2006/1/2 15",
-		"2006/01/02 15",
-		
-		// 年月日格式
-		"2006/1/2",
-		"2006/01/02",
-		"2006/1/2 0:00",
-		"2006/01/02 0:00",
-		
-		// 年月格式
-		"2006/1",
-		"2006/01",
-		
-		// 年格式
-		"2006/",
-	}
-
-	// 尝试解析"/"格式的日期
-	for _, format := range slashFormats {
-		if t, err := time.Parse(format, value); err == nil {
-			// 根据原始格式的组成部分决定输出格式
-			if strings.Contains(format, "15:04:05") || t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 {
-				// 包含时分秒
-				return t.Format("2006-01-02 15:04:05"), true
-			} else if strings.Contains(format, "15:04") || t.Hour() != 0 || t.Minute() != 0 {
-				// 包含时分
-				return t.Format("2006-01-02 15:04"), true
-			} else if strings.Contains(format, "15") || t.Hour() != 0 {
-				// 只包含时
-				return t.Format("2006-01-02 15"), true
-			} else if strings.Contains(format, "1/2") || strings.Contains(format, "01/02") {
-				// 包含年月日
-				return t.Format("2006-01-02"), true
-			} else if strings.Contains(format, "2006/1") || strings.Contains(format, "2006/01") {
-				// 只包含年月
-				return t.Format("2006-01"), true
-			} else if strings.Contains(format, "2006/") {
-				// 只包含年
-				return t.Format("2006"), true
-			}
-			
-			// 默认返回年月日格式
-			return t.Format("2006-01-02"), true
-		}
-	}
-
-	// 如果无法解析为日期，保持原样
-	return value, true
-}
-
-// isCommaList 检查字符串是否为逗号分隔的列表 (已被utils.IsCommaList替代，但保留以兼容现有代码)
-func isCommaList(value string) bool {
-	return utils.IsCommaList(value)
-}
-
-// processCommaList 将逗号分隔的字符串转换为字符串数组 (已被utils.ProcessCommaList替代，但保留以兼容现有代码)
-func processCommaList(value string) []string {
-	return utils.ProcessCommaList(value)
-}
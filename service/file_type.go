@@ -0,0 +1,294 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"file-url-parser/model"
+	"mime"
+	"strings"
+
+	"github.com/richardlehane/mscfb"
+)
+
+// 文件内容检测的结果中，不对应支持的文件类型的结果
+const (
+	sniffUnknown   = ""          // 无法识别的二进制内容
+	sniffText      = "text"      // 文本内容，具体类型根据扩展名、Content-Type或内容判断
+	sniffHTML      = "html"      // HTML页面
+	sniffEncrypted = "encrypted" // 加密的Office文件
+	sniffOLE2      = "ole2"      // 不是Excel或Word的OLE2复合文档
+	sniffZip       = ".zip"      // 不是Office文档的ZIP压缩包
+	sniffPPTX      = ".pptx"     // PowerPoint文档
+)
+
+const (
+	sniffSampleSize    = 8 * 1024  // 判断是否为文本时检查的字节数
+	textTypeSampleSize = 64 * 1024 // 区分CSV和纯文本时检查的字节数
+	pdfHeaderRange     = 1024      // PDF文件头之前允许的空白字符的范围
+)
+
+// zipSignature ZIP文件的本地文件头标识
+var zipSignature = []byte("PK\x03\x04")
+
+// binaryFileTypes 可以通过文件头识别的二进制文件类型，扩展名为这些类型时内容必须相符
+var binaryFileTypes = map[string]bool{
+	".xlsx": true,
+	".xls":  true,
+	".docx": true,
+	".doc":  true,
+	".pdf":  true,
+}
+
+// textFileTypes 文本文件类型
+var textFileTypes = map[string]bool{
+	".csv": true,
+	".tsv": true,
+	".psv": true,
+	".txt": true,
+	".md":  true,
+}
+
+// contentTypeFileTypes Content-Type对应的文件类型
+var contentTypeFileTypes = map[string]string{
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": ".xlsx",
+	"application/vnd.ms-excel": ".xls",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": ".docx",
+	"application/msword":        ".doc",
+	"application/pdf":           ".pdf",
+	"text/csv":                  ".csv",
+	"application/csv":           ".csv",
+	"text/tab-separated-values": ".tsv",
+	"text/markdown":             ".md",
+	"text/x-markdown":           ".md",
+	"text/plain":                ".txt",
+}
+
+// 文件内容无法按文件类型解析时的错误代码
+const (
+	FileTypeCodeHTML      = "CONTENT_IS_HTML"    // 下载到的是HTML页面
+	FileTypeCodeMismatch  = "FILE_TYPE_MISMATCH" // 文件内容与扩展名的文件类型不符
+	FileTypeCodeEncrypted = "FILE_ENCRYPTED"     // 加密的Office文件
+)
+
+// FileTypeError 文件内容无法按文件类型解析的错误
+type FileTypeError struct {
+	Code    string // 错误代码
+	Message string // 错误说明
+}
+
+// Error 返回错误说明
+func (e *FileTypeError) Error() string {
+	return e.Message
+}
+
+// FileTypeErrorCode 返回错误链中文件类型错误的代码，不是文件类型错误时返回空字符串
+func FileTypeErrorCode(err error) string {
+	var fileTypeErr *FileTypeError
+	if errors.As(err, &fileTypeErr) {
+		return fileTypeErr.Code
+	}
+	return ""
+}
+
+// errHTMLContent 下载到的是HTML页面（如登录页、错误页），而不是文件
+var errHTMLContent = &FileTypeError{Code: FileTypeCodeHTML, Message: "下载的内容是HTML页面而不是文件，链接可能需要登录或已过期"}
+
+// errEncryptedFile 加密的Office文件
+var errEncryptedFile = &FileTypeError{Code: FileTypeCodeEncrypted, Message: "不支持加密的文件"}
+
+// resolveFileType 根据文件内容、Content-Type和扩展名确定文件类型，结果写入fileInfo
+// override为请求中指定的文件类型，指定时直接使用；否则优先按文件头识别，文本文件再按扩展名、Content-Type和内容区分
+func resolveFileType(data []byte, fileInfo *model.FileInfo, override string) error {
+	if override != "" {
+		fileInfo.FileType, fileInfo.TypeSource = override, model.FileTypeSourceRequest
+		return nil
+	}
+
+	extension := fileInfo.FileType
+	contentType := contentTypeFileType(fileInfo.ContentType)
+	if len(data) == 0 {
+		// 空文件无法按内容判断
+		fileInfo.TypeSource = model.FileTypeSourceExtension
+		return nil
+	}
+
+	switch sniffed := sniffFileType(data); sniffed {
+	case sniffEncrypted:
+		return errEncryptedFile
+	case sniffHTML:
+		// 扩展名为文本类型时按文本处理（如保存为.txt的网页源代码）
+		if !textFileTypes[extension] {
+			return errHTMLContent
+		}
+		fileInfo.TypeSource = model.FileTypeSourceExtension
+	case sniffText:
+		switch {
+		case textFileTypes[extension]:
+			fileInfo.TypeSource = model.FileTypeSourceExtension
+		case textFileTypes[contentType]:
+			fileInfo.FileType, fileInfo.TypeSource = contentType, model.FileTypeSourceContentType
+		default:
+			fileInfo.FileType, fileInfo.TypeSource = guessTextFileType(data), model.FileTypeSourceContent
+		}
+	case sniffUnknown, sniffOLE2:
+		// 无法识别的内容不能按Excel、Word、PDF解析
+		if binaryFileTypes[extension] {
+			return &FileTypeError{
+				Code:    FileTypeCodeMismatch,
+				Message: "文件内容与文件类型不符: 扩展名为" + extension + "，但文件内容不是该格式",
+			}
+		}
+		fileInfo.TypeSource = model.FileTypeSourceExtension
+	default:
+		fileInfo.FileType, fileInfo.TypeSource = sniffed, model.FileTypeSourceContent
+	}
+	return nil
+}
+
+// sniffFileType 根据文件头识别文件类型：ZIP（区分xlsx、docx）、OLE2（区分xls、doc）、PDF、HTML和文本
+func sniffFileType(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, zipSignature):
+		return sniffZipType(data)
+	case bytes.HasPrefix(data, ole2Signature):
+		return sniffOLE2Type(data)
+	case isPDFHeader(data):
+		return ".pdf"
+	}
+
+	sample := data[:min(len(data), sniffSampleSize)]
+	if !isTextSample(sample) {
+		return sniffUnknown
+	}
+	if isHTMLSample(sample) {
+		return sniffHTML
+	}
+	return sniffText
+}
+
+// isPDFHeader 判断文件是否以PDF文件头（%PDF-）开始，文件头之前只允许BOM和空白字符
+// 不在文件开头查找，避免内容中提到 %PDF- 的文本文件被识别为PDF
+func isPDFHeader(data []byte) bool {
+	head := bytes.TrimPrefix(data[:min(len(data), pdfHeaderRange)], []byte{0xEF, 0xBB, 0xBF})
+	return bytes.HasPrefix(bytes.TrimLeft(head, " \t\r\n\f"), []byte("%PDF-"))
+}
+
+// sniffZipType 根据ZIP压缩包中的文件区分Office Open XML文档的类型
+func sniffZipType(data []byte) string {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return sniffUnknown
+	}
+	for _, file := range reader.File {
+		switch {
+		case strings.HasPrefix(file.Name, "xl/"):
+			return ".xlsx"
+		case strings.HasPrefix(file.Name, "word/"):
+			return ".docx"
+		case strings.HasPrefix(file.Name, "ppt/"):
+			return sniffPPTX
+		}
+	}
+	return sniffZip
+}
+
+// sniffOLE2Type 根据OLE2复合文档中的数据流区分Excel和Word文档
+// 加密的Office Open XML文档（设置了打开密码的.xlsx、.docx）同样保存为OLE2复合文档
+func sniffOLE2Type(data []byte) string {
+	doc, err := mscfb.New(bytes.NewReader(data))
+	if err != nil {
+		return sniffUnknown
+	}
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		switch entry.Name {
+		case "Workbook", "Book":
+			return ".xls"
+		case "WordDocument":
+			return ".doc"
+		case "EncryptedPackage":
+			return sniffEncrypted
+		}
+	}
+	return sniffOLE2
+}
+
+// isTextSample 判断样本是否为文本：有BOM或UTF-16特征，或者没有零字节且控制字符很少
+func isTextSample(sample []byte) bool {
+	if bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}) ||
+		bytes.HasPrefix(sample, []byte{0xFF, 0xFE}) ||
+		bytes.HasPrefix(sample, []byte{0xFE, 0xFF}) ||
+		detectUTF16(sample) != "" {
+		return true
+	}
+
+	controls := 0
+	for _, b := range sample {
+		switch {
+		case b == 0:
+			return false
+		case b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f' && b != 0x1B:
+			controls++
+		}
+	}
+	// 允许少量控制字符（如个别的垂直制表符）
+	return controls*100 <= len(sample)
+}
+
+// isHTMLSample 判断文本样本是否为HTML页面
+func isHTMLSample(sample []byte) bool {
+	text := strings.TrimPrefix(string(sample), "\ufeff")
+	text = strings.ToLower(strings.TrimSpace(text))
+	for _, prefix := range []string{"<!doctype html", "<html", "<head", "<body", "<script"} {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// guessTextFileType 根据内容判断没有扩展名的文本文件的类型
+// 大部分行（至少两行）的字段数相同且多于一个的分隔文本为CSV（或TSV、PSV），否则为纯文本
+func guessTextFileType(data []byte) string {
+	sample := data[:min(len(data), textTypeSampleSize)]
+	text, _, err := decodeBytes(sample, "")
+	if err != nil {
+		return ".txt"
+	}
+	truncated := len(data) > len(sample)
+	if truncated {
+		// 样本末尾可能是不完整的行
+		if idx := strings.LastIndexByte(text, '\n'); idx >= 0 {
+			text = text[:idx+1]
+		}
+	}
+
+	lines := 0
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines++
+		}
+	}
+	dialect := detectCSVDialect([]byte(text), false, "", model.CSVOptions{})
+	score, _ := scoreDelimiter(text, dialect)
+	if score < 2 || score*5 < min(lines, dialectSampleRecords)*4 {
+		return ".txt"
+	}
+	switch dialect.delimiter {
+	case '\t':
+		return ".tsv"
+	case '|':
+		return ".psv"
+	default:
+		return ".csv"
+	}
+}
+
+// contentTypeFileType 返回Content-Type对应的文件类型，无法对应时返回空字符串
+func contentTypeFileType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return contentTypeFileTypes[mediaType]
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"file-url-parser/model"
+	"testing"
+	"unicode/utf16"
+)
+
+// zipFile 生成包含指定文件的ZIP压缩包
+func zipFile(t *testing.T, names ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, name := range names {
+		if _, err := writer.Create(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// ole2File 生成只包含一个空数据流的OLE2复合文档（512字节的扇区：文件头、FAT扇区和目录扇区）
+func ole2File(streamName string) []byte {
+	const (
+		freeSector = 0xFFFFFFFF
+		endOfChain = 0xFFFFFFFE
+		fatSector  = 0xFFFFFFFD
+	)
+	data := make([]byte, 512*3)
+	header := data[:512]
+	copy(header, ole2Signature)
+	binary.LittleEndian.PutUint16(header[24:], 0x003E)
+	binary.LittleEndian.PutUint16(header[26:], 0x0003)
+	binary.LittleEndian.PutUint16(header[28:], 0xFFFE)
+	binary.LittleEndian.PutUint16(header[30:], 9)
+	binary.LittleEndian.PutUint16(header[32:], 6)
+	binary.LittleEndian.PutUint32(header[44:], 1) // FAT扇区数
+	binary.LittleEndian.PutUint32(header[48:], 1) // 目录的起始扇区
+	binary.LittleEndian.PutUint32(header[56:], 4096)
+	binary.LittleEndian.PutUint32(header[60:], endOfChain)
+	binary.LittleEndian.PutUint32(header[68:], endOfChain)
+	for i := 0; i < 109; i++ {
+		binary.LittleEndian.PutUint32(header[76+i*4:], freeSector)
+	}
+	binary.LittleEndian.PutUint32(header[76:], 0) // FAT位于扇区0
+
+	fat := data[512:1024]
+	for i := 0; i < 128; i++ {
+		binary.LittleEndian.PutUint32(fat[i*4:], freeSector)
+	}
+	binary.LittleEndian.PutUint32(fat[0:], fatSector)
+	binary.LittleEndian.PutUint32(fat[4:], endOfChain)
+
+	entry := func(index int, name string, entryType byte, child uint32) {
+		dir := data[1024+index*128 : 1024+(index+1)*128]
+		units := utf16.Encode([]rune(name))
+		for i, unit := range units {
+			binary.LittleEndian.PutUint16(dir[i*2:], unit)
+		}
+		binary.LittleEndian.PutUint16(dir[64:], uint16(len(units)*2+2))
+		dir[66], dir[67] = entryType, 1
+		binary.LittleEndian.PutUint32(dir[68:], freeSector)
+		binary.LittleEndian.PutUint32(dir[72:], freeSector)
+		binary.LittleEndian.PutUint32(dir[76:], child)
+		binary.LittleEndian.PutUint32(dir[116:], endOfChain)
+	}
+	entry(0, "Root Entry", 5, 1)
+	entry(1, streamName, 2, freeSector)
+	for i := 2; i < 4; i++ {
+		dir := data[1024+i*128:]
+		binary.LittleEndian.PutUint32(dir[68:], freeSector)
+		binary.LittleEndian.PutUint32(dir[72:], freeSector)
+		binary.LittleEndian.PutUint32(dir[76:], freeSector)
+	}
+	return data
+}
+
+// TestSniffFileType 按文件头识别ZIP、OLE2、PDF、HTML和文本内容
+func TestSniffFileType(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"xlsx", zipFile(t, "[Content_Types].xml", "xl/workbook.xml"), ".xlsx"},
+		{"docx", zipFile(t, "[Content_Types].xml", "word/document.xml"), ".docx"},
+		{"pptx", zipFile(t, "ppt/presentation.xml"), sniffPPTX},
+		{"普通ZIP", zipFile(t, "readme.txt"), sniffZip},
+		{"损坏的ZIP", append([]byte("PK\x03\x04"), make([]byte, 100)...), sniffUnknown},
+		{"xls", ole2File("Workbook"), ".xls"},
+		{"BIFF5的xls", ole2File("Book"), ".xls"},
+		{"doc", ole2File("WordDocument"), ".doc"},
+		{"加密的Office文件", ole2File("EncryptedPackage"), sniffEncrypted},
+		{"其他OLE2文档", ole2File("PowerPoint Document"), sniffOLE2},
+		{"PDF", []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n"), ".pdf"},
+		{"空白之后的PDF", []byte("\r\n  %PDF-1.4\n"), ".pdf"},
+		{"HTML", []byte("<!DOCTYPE html><html><body>登录</body></html>"), sniffHTML},
+		{"空白之后的HTML", []byte("\n\n   <html lang=\"zh\">\n<head>"), sniffHTML},
+		{"BOM之后的HTML", []byte("\xef\xbb\xbf<!doctype HTML>\n<html>"), sniffHTML},
+		{"BOM和空白之后的HTML", []byte("\xef\xbb\xbf \r\n<script>location.href='/login'</script>"), sniffHTML},
+		{"CSV", []byte("name,qty\na,1\n"), sniffText},
+		{"包含HTML的CSV", []byte("name,html\na,<html>\n"), sniffText},
+		{"UTF-16文本", []byte("\xff\xfen\x00a\x00m\x00e\x00"), sniffText},
+		{"PNG图片", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x01\x00"), sniffUnknown},
+	}
+	for _, tt := range tests {
+		if got := sniffFileType(tt.data); got != tt.want {
+			t.Errorf("%s: 识别为 %q，应为 %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestIsPDFHeader PDF文件头之前只允许BOM和空白字符
+func TestIsPDFHeader(t *testing.T) {
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{"%PDF-1.7", true},
+		{"\xef\xbb\xbf%PDF-1.4", true},
+		{" \t\r\n\f%PDF-1.4", true},
+		{"\xef\xbb\xbf\n%PDF-1.4", true},
+		{"", false},
+		{"%PDF", false},
+		{"%pdf-1.4", false},
+		{"\x00%PDF-1.4", false},
+		{"说明：文件以 %PDF-1.4 开头", false},
+		{"name,note\na,%PDF-1.4\n", false},
+		{string(bytes.Repeat([]byte(" "), pdfHeaderRange)) + "%PDF-1.4", false},
+	}
+	for _, tt := range tests {
+		if got := isPDFHeader([]byte(tt.data)); got != tt.want {
+			t.Errorf("isPDFHeader(%q) = %v，应为 %v", tt.data, got, tt.want)
+		}
+	}
+}
+
+// TestResolveFileTypeErrors HTML页面、加密文件和内容与扩展名不符的文件返回带错误代码的错误
+func TestResolveFileTypeErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		extension string
+		want      string
+	}{
+		{"HTML页面", []byte(" <html><body>请登录</body></html>"), ".xlsx", FileTypeCodeHTML},
+		{"没有扩展名的HTML页面", []byte("<!DOCTYPE html>"), "", FileTypeCodeHTML},
+		{"扩展名为文本类型的HTML", []byte("<html></html>"), ".txt", ""},
+		{"加密的文件", ole2File("EncryptedPackage"), ".xlsx", FileTypeCodeEncrypted},
+		{"内容不是xlsx", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), ".xlsx", FileTypeCodeMismatch},
+		{"内容不是xls的OLE2文档", ole2File("PowerPoint Document"), ".xls", FileTypeCodeMismatch},
+		{"扩展名错误的xlsx", zipFile(t, "xl/workbook.xml"), ".xls", ""},
+	}
+	for _, tt := range tests {
+		fileInfo := &model.FileInfo{FileType: tt.extension}
+		err := resolveFileType(tt.data, fileInfo, "")
+		if code := FileTypeErrorCode(err); code != tt.want {
+			t.Errorf("%s: 错误代码为 %q（%v），应为 %q", tt.name, code, err, tt.want)
+		}
+	}
+}
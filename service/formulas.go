@@ -0,0 +1,99 @@
+package service
+
+import (
+	"errors"
+	"file-url-parser/model"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// errFormulasXLS .xls文件的公式以解析后的标记保存，不支持读取公式文本和重新计算
+var errFormulasXLS = errors.New("只有.xlsx文件支持formulas参数的text、both和calculate模式")
+
+// formulaReader 读取.xlsx工作表中各行的公式
+// excelize按单元格读取公式，每次都会遍历已加载的工作表，因此只读取实际解析的行（预读行和分页数据行）
+type formulaReader struct {
+	file      *excelize.File
+	sheetName string
+	mode      string
+	width     int // 工作表数据范围的最后一列
+}
+
+// newFormulaReader 创建公式读取器，读取公式时会加载整个工作表
+func newFormulaReader(f *excelize.File, sheetName string, mode string) (*formulaReader, error) {
+	dimension, err := f.GetSheetDimension(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	return &formulaReader{file: f, sheetName: sheetName, mode: mode, width: dimensionLastCol(dimension)}, nil
+}
+
+// read 读取第rowNum行（从0开始）的公式，返回列索引 -> 公式文本（以"="开头），没有公式时返回nil
+func (r *formulaReader) read(rowNum int, rowLen int) (map[int]string, error) {
+	var formulas map[int]string
+	for col := 0; col < max(r.width, rowLen); col++ {
+		cellName, err := excelize.CoordinatesToCellName(col+1, rowNum+1)
+		if err != nil {
+			return nil, err
+		}
+		formula, err := r.file.GetCellFormula(r.sheetName, cellName)
+		if err != nil {
+			return nil, err
+		}
+		if formula == "" {
+			continue
+		}
+		if formulas == nil {
+			formulas = make(map[int]string)
+		}
+		formulas[col] = "=" + strings.TrimPrefix(formula, "=")
+	}
+	return formulas, nil
+}
+
+// calculate 计算没有缓存结果的公式单元格，将结果填入显示文本和原始值（raw为nil时不填）
+// 无法计算的公式（如不支持的函数）保持为空，公式错误输出错误值（如 #DIV/0!）
+func (r *formulaReader) calculate(rowNum int, row, raw []string, formulas map[int]string) ([]string, []string) {
+	for col := range formulas {
+		if cellText(row, col) != "" {
+			continue
+		}
+		cellName, err := excelize.CoordinatesToCellName(col+1, rowNum+1)
+		if err != nil {
+			continue
+		}
+		// 按单元格的数字格式显示计算结果
+		text, _ := r.file.CalcCellValue(r.sheetName, cellName)
+		if text == "" {
+			continue
+		}
+		row = setCellText(row, col, text)
+		if raw != nil {
+			value, _ := r.file.CalcCellValue(r.sheetName, cellName, excelize.Options{RawCellValue: true})
+			raw = setCellText(raw, col, value)
+		}
+	}
+	return row, raw
+}
+
+// setCellText 设置行中指定列的内容，行长度不足时补齐空单元格
+func setCellText(row []string, col int, text string) []string {
+	for len(row) <= col {
+		row = append(row, "")
+	}
+	row[col] = text
+	return row
+}
+
+// formulaCellValue 根据formulas参数得到公式单元格输出的值，value为按缓存结果转换的值
+func formulaCellValue(value interface{}, formula string, options model.ParseOptions) interface{} {
+	switch options.Formulas {
+	case model.FormulasText:
+		return formula
+	case model.FormulasBoth:
+		return model.FormulaValue{Value: value, Formula: formula}
+	default:
+		return value
+	}
+}
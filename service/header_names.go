@@ -0,0 +1,120 @@
+package service
+
+import (
+	"file-url-parser/model"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// buildHeaders 根据配置生成各列的数据键名
+// 使用表头作为键时去掉首尾空白并将换行等连续空白替换为一个空格，按header_case转换命名风格，
+// 空表头使用 Col_N，重复的键名依次添加 _2、_3 等后缀，保证每列的键名唯一
+func buildHeaders(originalHeaders []string, options model.ParseOptions) []string {
+	headers := make([]string, len(originalHeaders))
+	if !options.UseHeaderAsKey {
+		// 使用统一格式的表头 Col_1, Col_2, ...，保持原始顺序
+		for i := range originalHeaders {
+			// 使用1-based索引，与Excel列号保持一致
+			headers[i] = fmt.Sprintf("Col_%d", i+1)
+		}
+		return headers
+	}
+
+	for i, header := range originalHeaders {
+		name := strings.Join(strings.Fields(header), " ")
+		switch options.HeaderCase {
+		case model.HeaderCaseSnake:
+			name = toSnakeCase(name)
+		case model.HeaderCaseCamel:
+			name = toCamelCase(name)
+		}
+		if name == "" {
+			name = fmt.Sprintf("Col_%d", i+1)
+		}
+		headers[i] = name
+	}
+	return uniqueHeaders(headers)
+}
+
+// uniqueHeaders 为重复的键名添加 _2、_3 等后缀，生成的键名与其他列相同时继续递增
+func uniqueHeaders(headers []string) []string {
+	used := make(map[string]bool, len(headers))
+	for _, header := range headers {
+		used[header] = true
+	}
+
+	seen := make(map[string]int, len(headers))
+	result := make([]string, len(headers))
+	for i, header := range headers {
+		seen[header]++
+		if seen[header] == 1 {
+			result[i] = header
+			continue
+		}
+		suffix := seen[header]
+		name := header + "_" + strconv.Itoa(suffix)
+		for used[name] {
+			suffix++
+			name = header + "_" + strconv.Itoa(suffix)
+		}
+		seen[header] = suffix
+		used[name] = true
+		result[i] = name
+	}
+	return result
+}
+
+// splitWords 将表头拆分为单词：非字母数字的字符作为分隔符，并在大小写变化处拆分（如 "OrderID" → "Order"、"ID"）
+func splitWords(value string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(value)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		}
+		if len(word) > 0 && unicode.IsUpper(r) {
+			prev := word[len(word)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, string(word))
+				word = nil
+			}
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// toSnakeCase 转换为snake_case，如 "Order ID" → "order_id"
+func toSnakeCase(value string) string {
+	words := splitWords(value)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return strings.Join(words, "_")
+}
+
+// toCamelCase 转换为camelCase，如 "Order ID" → "orderId"
+func toCamelCase(value string) string {
+	words := splitWords(value)
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if i > 0 {
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			word = string(runes)
+		}
+		words[i] = word
+	}
+	return strings.Join(words, "")
+}
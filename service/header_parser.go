@@ -0,0 +1,189 @@
+package service
+
+import (
+	"file-url-parser/model"
+	"io"
+	"strings"
+)
+
+// maxHeaderRows 自动检测时最多合并的表头行数
+const maxHeaderRows = 5
+
+// detectHeaderRows 确定表头占用的行数，需要时继续从读取器预读行
+// options.HeaderRows大于0时使用指定的行数，否则从表头行开始检测下方的子表头行。
+// 返回表头行数、起始列（多行表头时取各表头行中最左侧的非空列）和预读的行
+func detectHeaderRows(reader rowReader, lookahead [][]string, headerIdx, startCol int, options model.ParseOptions) (int, int, [][]string, error) {
+	limit := options.HeaderRows
+	if limit <= 0 {
+		limit = maxHeaderRows
+	}
+
+	// 预读到表头之后的第一行
+	for len(lookahead) < headerIdx+limit+1 {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		lookahead = append(lookahead, row)
+	}
+
+	count := 1
+	if options.HeaderRows > 0 {
+		count = min(options.HeaderRows, len(lookahead)-headerIdx)
+	} else {
+		// 子表头行之后必须紧接着有内容（标题、备注等下方通常是空行）
+		for count < limit && headerIdx+count+1 < len(lookahead) && hasAnyValue(lookahead[headerIdx+count+1]) &&
+			isSubHeaderRow(lookahead[headerIdx+count-1], lookahead[headerIdx+count], options) {
+			count++
+		}
+	}
+
+	if count > 1 && options.Table.StartCol == 0 {
+		// 上层表头可能只从数据列的中间开始（如左侧的"地区"列只在下一行有表头）
+		for _, row := range lookahead[headerIdx : headerIdx+count] {
+			if col := firstNonEmptyCol(row); col != -1 && col < startCol {
+				startCol = col
+			}
+		}
+	}
+	return count, startCol, lookahead, nil
+}
+
+// isSubHeaderRow 判断next是否为表头行row下方的子表头行：
+// next只包含文本（没有数值和日期），且row中至少有一个分组单元格横跨next中两个以上有内容的列
+// （右侧的单元格为空；合并单元格横向填充时也可以是内容相同）
+func isSubHeaderRow(row, next []string, options model.ParseOptions) bool {
+	filledAcross := options.MergedCells == model.MergedCellsFillAcross || options.MergedCells == model.MergedCellsFillBoth
+
+	for _, cell := range next {
+		text := strings.TrimSpace(cell)
+		if text == "" {
+			continue
+		}
+		if _, ok := parseNumber(text, model.NumericModeAuto); ok {
+			return false
+		}
+		if _, ok := recognizeDate(text, options.Date); ok {
+			return false
+		}
+	}
+
+	for col := 0; col+1 < len(next); col++ {
+		group := cellText(row, col)
+		if group == "" || cellText(next, col) == "" || cellText(next, col+1) == "" {
+			continue
+		}
+		if right := cellText(row, col+1); right == "" || (filledAcross && right == group) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerPaths 计算多行表头中各列从上到下的表头路径
+// 上层表头的空单元格视为左侧分组的延续（合并单元格只有左上角有值），
+// 路径中相邻的相同名称（纵向合并后填充的值）只保留一个
+func headerPaths(rows [][]string, startCol int) [][]string {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row)-startCol)
+	}
+
+	grid := make([][]string, len(rows))
+	for level, row := range rows {
+		grid[level] = make([]string, width)
+		for col := range grid[level] {
+			grid[level][col] = cellText(row, startCol+col)
+		}
+	}
+
+	// hasBelow 检查列在指定层级之下是否还有表头
+	hasBelow := func(level, col int) bool {
+		for l := level + 1; l < len(grid); l++ {
+			if grid[l][col] != "" {
+				return true
+			}
+		}
+		return false
+	}
+	// sameParent 检查相邻两列在指定层级之上的表头是否相同
+	sameParent := func(level, col int) bool {
+		for l := 0; l < level; l++ {
+			if grid[l][col] != grid[l][col-1] {
+				return false
+			}
+		}
+		return true
+	}
+
+	for level := 0; level < len(grid)-1; level++ {
+		for col := 1; col < width; col++ {
+			if grid[level][col] == "" && grid[level][col-1] != "" &&
+				hasBelow(level, col) && hasBelow(level, col-1) && sameParent(level, col) {
+				grid[level][col] = grid[level][col-1]
+			}
+		}
+	}
+
+	paths := make([][]string, width)
+	for col := range paths {
+		path := []string{}
+		for level := range grid {
+			name := grid[level][col]
+			if name == "" || (len(path) > 0 && path[len(path)-1] == name) {
+				continue
+			}
+			path = append(path, name)
+		}
+		paths[col] = path
+	}
+	return paths
+}
+
+// buildHeaderTree 根据各列的表头路径构建表头层级结构，叶子节点记录该列的数据键名
+// 只有相邻的列才会合并到同一个分组下
+func buildHeaderTree(paths [][]string, keys []string) []model.HeaderNode {
+	tree := []model.HeaderNode{}
+	for col, path := range paths {
+		nodes := &tree
+		for level, name := range path {
+			if level == len(path)-1 {
+				break
+			}
+			if n := len(*nodes); n > 0 && (*nodes)[n-1].Name == name && (*nodes)[n-1].Children != nil {
+				nodes = &(*nodes)[n-1].Children
+				continue
+			}
+			*nodes = append(*nodes, model.HeaderNode{Name: name, Children: []model.HeaderNode{}})
+			nodes = &(*nodes)[len(*nodes)-1].Children
+		}
+
+		leaf := model.HeaderNode{Key: keys[col]}
+		if len(path) > 0 {
+			leaf.Name = path[len(path)-1]
+		}
+		*nodes = append(*nodes, leaf)
+	}
+	return tree
+}
+
+// firstNonEmptyCol 返回行中第一个非空单元格的列索引，没有时返回-1
+func firstNonEmptyCol(row []string) int {
+	for col, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return col
+		}
+	}
+	return -1
+}
+
+// cellText 返回去掉首尾空白的单元格内容，超出行长度时返回空字符串
+func cellText(row []string, col int) string {
+	if col < 0 || col >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[col])
+}
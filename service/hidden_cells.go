@@ -0,0 +1,251 @@
+package service
+
+import (
+	"errors"
+	"file-url-parser/model"
+	"io"
+	"sort"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// sheetPositionReader 跳过了隐藏行或隐藏列的读取器，能够将读取到的位置转换为工作表中的行号和列号
+type sheetPositionReader interface {
+	// SheetRow 返回第index行（从0开始，按读取顺序计）在工作表中的行号（从1开始）
+	SheetRow(index int) int
+	// SheetCol 返回读取到的第index列（从0开始）在工作表中的列号（从1开始）
+	SheetCol(index int) int
+}
+
+// visibleRowReader 跳过隐藏行和隐藏列的行读取器
+// 底层读取器按工作表的行号从第1行开始逐行读取，第n次读取的行即为工作表的第n行
+type visibleRowReader struct {
+	reader      typedRowReader
+	rowHidden   func(row int) (bool, error) // 行是否隐藏（行号从1开始），nil表示不跳过隐藏行
+	colHidden   func(col int) (bool, error) // 列是否隐藏（列索引从0开始），nil表示不跳过隐藏列
+	checkedCols int                         // 已检查可见性的列数
+	hiddenCols  []int                       // 已检查的列中的隐藏列（列索引从0开始，升序）
+	firstRow    int                         // 从该行开始跳过隐藏行，之前的行由指定的表头行按行号跳过
+	lastRow     int                         // 表格范围的最后一行，0表示不限制
+	sheetRow    int                         // 已读取或跳过的工作表行数
+	skippedRows []int                       // 跳过的隐藏行（行号从1开始，升序）
+}
+
+// parseVisibleTables 跳过隐藏行和隐藏列后解析表格，结果中报告跳过的行和列（只包括读取到的范围内的行列）
+// 指定的表格范围（行号、列号）按工作表中的位置计算，返回的表格位置同样转换为工作表中的位置
+func parseVisibleTables(reader typedRowReader, rowHidden, colHidden func(int) (bool, error), options model.ParseOptions) (ExcelParseResult, error) {
+	if rowHidden == nil && colHidden == nil {
+		return parseTables(reader, options)
+	}
+
+	visible := &visibleRowReader{
+		reader:    reader,
+		rowHidden: rowHidden,
+		colHidden: colHidden,
+		firstRow:  max(options.Table.HeaderRow, 1),
+		lastRow:   options.Table.EndRow,
+	}
+	// 转换指定的列号需要先检查之前各列的可见性
+	if err := visible.checkColumns(max(options.Table.StartCol, options.Table.EndCol)); err != nil {
+		return ExcelParseResult{}, err
+	}
+	options.Table = visible.visibleTableRange(options.Table)
+
+	result, err := parseTables(visible, options)
+	if err != nil {
+		return ExcelParseResult{}, err
+	}
+	result.SkippedRows = visible.skippedRows
+	for _, col := range visible.hiddenCols {
+		name, _ := excelize.ColumnNumberToName(col + 1)
+		result.SkippedColumns = append(result.SkippedColumns, name)
+	}
+	return result, nil
+}
+
+// Next 读取下一个可见行，隐藏列的单元格会被去掉
+func (r *visibleRowReader) Next() ([]string, error) {
+	for {
+		if r.lastRow > 0 && r.sheetRow >= r.lastRow {
+			return nil, io.EOF
+		}
+		row, err := r.reader.Next()
+		if err != nil {
+			return nil, err
+		}
+		r.sheetRow++
+		hidden, err := r.hidden()
+		if err != nil {
+			return nil, err
+		}
+		if hidden {
+			continue
+		}
+		if err := r.checkColumns(len(row)); err != nil {
+			return nil, err
+		}
+		return removeColumns(row, r.hiddenCols), nil
+	}
+}
+
+// Skip 跳过下一个可见行
+func (r *visibleRowReader) Skip() error {
+	for {
+		if r.lastRow > 0 && r.sheetRow >= r.lastRow {
+			return io.EOF
+		}
+		if err := r.reader.Skip(); err != nil {
+			return err
+		}
+		r.sheetRow++
+		hidden, err := r.hidden()
+		if err != nil || !hidden {
+			return err
+		}
+	}
+}
+
+// hidden 检查刚读取的行是否为需要跳过的隐藏行，是则记录该行
+func (r *visibleRowReader) hidden() (bool, error) {
+	if r.rowHidden == nil || r.sheetRow < r.firstRow {
+		return false, nil
+	}
+	hidden, err := r.rowHidden(r.sheetRow)
+	if err != nil || !hidden {
+		return false, err
+	}
+	r.skippedRows = append(r.skippedRows, r.sheetRow)
+	return true, nil
+}
+
+// checkColumns 检查前width列中尚未检查的列是否隐藏
+// 行的宽度只会在读取到更宽的行时增加，隐藏列按列索引升序追加
+func (r *visibleRowReader) checkColumns(width int) error {
+	if r.colHidden == nil {
+		return nil
+	}
+	for ; r.checkedCols < width; r.checkedCols++ {
+		hidden, err := r.colHidden(r.checkedCols)
+		if err != nil {
+			return err
+		}
+		if hidden {
+			r.hiddenCols = append(r.hiddenCols, r.checkedCols)
+		}
+	}
+	return nil
+}
+
+// CellValues 返回指定的可见行的类型化单元格值，隐藏列的单元格会被去掉
+func (r *visibleRowReader) CellValues(rowNum int) []typedCell {
+	cells := r.reader.CellValues(r.SheetRow(rowNum) - 1)
+	// 公式、附加信息等可能超出行的显示文本的宽度，检查失败时按可见处理
+	_ = r.checkColumns(len(cells))
+	return removeColumns(cells, r.hiddenCols)
+}
+
+// SheetRow 返回可见行在工作表中的行号
+func (r *visibleRowReader) SheetRow(index int) int {
+	row := index + 1
+	for _, skipped := range r.skippedRows {
+		if skipped > row {
+			break
+		}
+		row++
+	}
+	return row
+}
+
+// SheetCol 返回可见列在工作表中的列号
+func (r *visibleRowReader) SheetCol(index int) int {
+	col := index
+	for _, hidden := range r.hiddenCols {
+		if hidden > col {
+			break
+		}
+		col++
+	}
+	return col + 1
+}
+
+// visibleTableRange 将指定的起始列和结束列转换为去掉隐藏列后的列号
+// 行号不转换：表头行之前的行按行号跳过，结束行由读取器按行号判断
+func (r *visibleRowReader) visibleTableRange(tableRange model.TableRange) model.TableRange {
+	// hiddenBefore 计算列号之前（不含）的隐藏列数量
+	hiddenBefore := func(col int) int {
+		return sort.SearchInts(r.hiddenCols, col-1)
+	}
+	if tableRange.StartCol > 0 {
+		tableRange.StartCol -= hiddenBefore(tableRange.StartCol)
+	}
+	if tableRange.EndCol > 0 {
+		tableRange.EndCol -= hiddenBefore(tableRange.EndCol + 1)
+	}
+	return tableRange
+}
+
+// sheetOrigin 将按读取位置计算的表格起始位置转换为工作表中的位置，positions为nil时不转换
+func sheetOrigin(origin *model.TableOrigin, positions sheetPositionReader) {
+	if origin == nil || positions == nil {
+		return
+	}
+	origin.HeaderRow = positions.SheetRow(origin.HeaderRow - 1)
+	origin.StartCol = positions.SheetCol(origin.StartCol - 1)
+	origin.Cell, _ = excelize.CoordinatesToCellName(origin.StartCol, origin.HeaderRow)
+}
+
+// removeColumns 去掉行中指定索引（升序）的列
+func removeColumns[T any](row []T, cols []int) []T {
+	if len(cols) == 0 || row == nil {
+		return row
+	}
+	result := make([]T, 0, len(row))
+	next := 0
+	for col, cell := range row {
+		for next < len(cols) && cols[next] < col {
+			next++
+		}
+		if next < len(cols) && cols[next] == col {
+			continue
+		}
+		result = append(result, cell)
+	}
+	return result
+}
+
+// visibleSheetList 返回工作簿中的工作表，不包括隐藏工作表时同时返回跳过的隐藏工作表
+func visibleSheetList(f workbook, options model.ParseOptions) ([]string, []string, error) {
+	sheetNames := f.GetSheetList()
+	if options.IncludeHidden.Sheets {
+		return sheetNames, nil, nil
+	}
+
+	var visibleSheets, hiddenSheets []string
+	for _, sheetName := range sheetNames {
+		visible, err := f.GetSheetVisible(sheetName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if visible {
+			visibleSheets = append(visibleSheets, sheetName)
+		} else {
+			hiddenSheets = append(hiddenSheets, sheetName)
+		}
+	}
+	return visibleSheets, hiddenSheets, nil
+}
+
+// checkSheetVisible 不包括隐藏工作表时，检查指定的工作表是否可见
+func checkSheetVisible(f workbook, sheetName string, options model.ParseOptions) error {
+	if options.IncludeHidden.Sheets {
+		return nil
+	}
+	visible, err := f.GetSheetVisible(sheetName)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return errors.New("工作表已隐藏: " + sheetName)
+	}
+	return nil
+}
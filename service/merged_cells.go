@@ -0,0 +1,180 @@
+package service
+
+import (
+	"file-url-parser/model"
+	"sort"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// mergeRange 合并单元格区域（行列索引从0开始，包含结束行列）
+type mergeRange struct {
+	startRow, endRow int
+	startCol, endCol int
+	value            string // 左上角单元格的显示文本
+	raw              string // 左上角单元格的原始值
+	style            int    // 左上角单元格的样式ID，填充的单元格按该样式确定类型
+}
+
+// fills 判断合并区域中的单元格是否需要按填充方式填充左上角单元格的值
+func (m mergeRange) fills(row, col int, mode string) bool {
+	if row < m.startRow || row > m.endRow || col < m.startCol || col > m.endCol {
+		return false
+	}
+	if row == m.startRow && col == m.startCol {
+		// 左上角单元格本身已经有值
+		return false
+	}
+	switch mode {
+	case model.MergedCellsFillDown:
+		return col == m.startCol
+	case model.MergedCellsFillAcross:
+		return row == m.startRow
+	case model.MergedCellsFillBoth:
+		return true
+	}
+	return false
+}
+
+// excelizeMergeRanges 读取.xlsx工作表的合并单元格区域
+// withRaw为true时同时读取左上角单元格的原始值和样式ID，用于按单元格类型转换
+func excelizeMergeRanges(f *excelize.File, sheetName string, withRaw bool) ([]mergeRange, error) {
+	mergeCells, err := f.GetMergeCells(sheetName)
+	if err != nil {
+		return nil, err
+	}
+
+	merges := make([]mergeRange, 0, len(mergeCells))
+	for _, mergeCell := range mergeCells {
+		startCol, startRow, err := excelize.CellNameToCoordinates(mergeCell.GetStartAxis())
+		if err != nil {
+			return nil, err
+		}
+		endCol, endRow, err := excelize.CellNameToCoordinates(mergeCell.GetEndAxis())
+		if err != nil {
+			return nil, err
+		}
+
+		merge := mergeRange{
+			startRow: startRow - 1,
+			endRow:   endRow - 1,
+			startCol: startCol - 1,
+			endCol:   endCol - 1,
+			value:    mergeCell.GetCellValue(),
+		}
+		if merge.value == "" {
+			continue
+		}
+		if withRaw {
+			if merge.raw, err = f.GetCellValue(sheetName, mergeCell.GetStartAxis(), excelize.Options{RawCellValue: true}); err != nil {
+				return nil, err
+			}
+			if merge.style, err = f.GetCellStyle(sheetName, mergeCell.GetStartAxis()); err != nil {
+				return nil, err
+			}
+		}
+		merges = append(merges, merge)
+	}
+
+	sort.Slice(merges, func(i, j int) bool {
+		return merges[i].startRow < merges[j].startRow
+	})
+	return merges, nil
+}
+
+// mergedCellFiller 按行顺序为流式读取的行填充合并单元格
+type mergedCellFiller struct {
+	mode   string
+	merges []mergeRange // 按起始行排序
+	next   int          // 下一个尚未开始的合并区域
+	active []mergeRange // 包含当前行的合并区域
+}
+
+// advance 移动到第rowNum行，更新包含该行的合并区域，rowNum必须递增
+func (f *mergedCellFiller) advance(rowNum int) {
+	active := f.active[:0]
+	for _, merge := range f.active {
+		if merge.endRow >= rowNum {
+			active = append(active, merge)
+		}
+	}
+	for ; f.next < len(f.merges) && f.merges[f.next].startRow <= rowNum; f.next++ {
+		if f.merges[f.next].endRow >= rowNum {
+			active = append(active, f.merges[f.next])
+		}
+	}
+	f.active = active
+}
+
+// fillRow 填充当前行（第rowNum行）中属于合并区域的单元格，raw为true时填充原始值
+func (f *mergedCellFiller) fillRow(row []string, rowNum int, raw bool) []string {
+	for _, merge := range f.active {
+		value := merge.value
+		if raw {
+			value = merge.raw
+		}
+		for col := merge.startCol; col <= merge.endCol; col++ {
+			if !merge.fills(rowNum, col, f.mode) {
+				continue
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+			row[col] = value
+		}
+	}
+	return row
+}
+
+// fillStyles 为当前行（第rowNum行）中属于合并区域的单元格填充左上角单元格的样式ID
+func (f *mergedCellFiller) fillStyles(styles []int, rowNum int) []int {
+	for _, merge := range f.active {
+		for col := merge.startCol; col <= merge.endCol; col++ {
+			if !merge.fills(rowNum, col, f.mode) {
+				continue
+			}
+			for len(styles) <= col {
+				styles = append(styles, 0)
+			}
+			styles[col] = merge.style
+		}
+	}
+	return styles
+}
+
+// fillMergedRows 为已读取到内存中的行填充合并单元格，填充的单元格使用左上角单元格的类型化值
+// 合并区域来自文件内容，按已读取的行数和最长一行的列数截断，起止行列颠倒或超出范围的区域被忽略
+func fillMergedRows(rows [][]string, cells [][]typedCell, merges []mergeRange, mode string) {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	for _, merge := range merges {
+		if merge.startRow < 0 || merge.startCol < 0 || merge.endRow < merge.startRow || merge.endCol < merge.startCol {
+			continue
+		}
+		if merge.startRow >= len(rows) || merge.startCol >= len(rows[merge.startRow]) {
+			continue
+		}
+		merge.endRow = min(merge.endRow, len(rows)-1)
+		merge.endCol = min(merge.endCol, width-1)
+
+		value, cell := rows[merge.startRow][merge.startCol], cells[merge.startRow][merge.startCol]
+		if value == "" {
+			continue
+		}
+		for row := merge.startRow; row <= merge.endRow; row++ {
+			for col := merge.startCol; col <= merge.endCol; col++ {
+				if !merge.fills(row, col, mode) {
+					continue
+				}
+				for len(rows[row]) <= col {
+					rows[row] = append(rows[row], "")
+					cells[row] = append(cells[row], typedCell{})
+				}
+				rows[row][col] = value
+				cells[row][col] = cell
+			}
+		}
+	}
+}
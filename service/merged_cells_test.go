@@ -0,0 +1,68 @@
+package service
+
+import (
+	"file-url-parser/model"
+	"reflect"
+	"testing"
+)
+
+// TestFillMergedRowsBounds 合并区域按已读取的行数和列数截断，无效的区域被忽略
+func TestFillMergedRowsBounds(t *testing.T) {
+	rows := [][]string{
+		{"标题", "", "x"},
+		{"a", "b"},
+		{"c"},
+	}
+	cells := make([][]typedCell, len(rows))
+	for i, row := range rows {
+		cells[i] = make([]typedCell, len(row))
+		for j, value := range row {
+			cells[i][j] = typedCell{raw: value}
+		}
+	}
+	merges := []mergeRange{
+		{startRow: 0, endRow: 65535, startCol: 0, endCol: 255}, // 超出工作表范围，截断到3行3列
+		{startRow: 1, endRow: 0, startCol: 1, endCol: 1},       // 起止行颠倒
+		{startRow: 1, endRow: 2, startCol: 1, endCol: 0},       // 起止列颠倒
+		{startRow: 10, endRow: 20, startCol: 0, endCol: 1},     // 起始行超出范围
+		{startRow: 2, endRow: 2, startCol: 5, endCol: 6},       // 起始列超出范围
+	}
+	fillMergedRows(rows, cells, merges, model.MergedCellsFillBoth)
+
+	want := [][]string{
+		{"标题", "标题", "标题"},
+		{"标题", "标题", "标题"},
+		{"标题", "标题", "标题"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("填充后的行为 %q，应为 %q", rows, want)
+	}
+	for i := range rows {
+		if len(cells[i]) != len(rows[i]) {
+			t.Errorf("第%d行的类型化值有 %d 列，应与显示文本的 %d 列一致", i+1, len(cells[i]), len(rows[i]))
+		}
+	}
+}
+
+// TestXLSMergeCellsInvalid .xls文件中起止行列颠倒和超出256列的合并区域被忽略
+func TestXLSMergeCellsInvalid(t *testing.T) {
+	data := xlsUint16s(4,
+		0, 1, 0, 1, // A1:B2
+		5, 2, 0, 0, // 起止行颠倒
+		0, 0, 300, 301, // 超出256列
+		0, 0, 254, 1000, // 结束列截断到第256列
+	)
+	wb := xlsTestWorkbook(t, nil, xlsRecord(xlsRecordMergeCells, data))
+
+	merges, err := wb.GetMergeCells("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []mergeRange{
+		{startRow: 0, endRow: 1, startCol: 0, endCol: 1},
+		{startRow: 0, endRow: 0, startCol: 254, endCol: xlsMaxColumns - 1},
+	}
+	if !reflect.DeepEqual(merges, want) {
+		t.Errorf("合并区域为 %+v，应为 %+v", merges, want)
+	}
+}
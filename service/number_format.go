@@ -0,0 +1,180 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// numberFormatKind 数字格式的类别
+type numberFormatKind int
+
+const (
+	numberFormatGeneral  numberFormatKind = iota // 常规或其他数值格式
+	numberFormatDate                             // 日期
+	numberFormatTime                             // 时间
+	numberFormatDateTime                         // 日期时间
+	numberFormatPercent                          // 百分比
+	numberFormatCurrency                         // 货币或会计格式
+)
+
+// builtInNumberFormats 内置数字格式（只列出影响类型判断的格式）
+var builtInNumberFormats = map[int]string{
+	5:  `"$"#,##0_);\("$"#,##0\)`,
+	6:  `"$"#,##0_);[Red]\("$"#,##0\)`,
+	7:  `"$"#,##0.00_);\("$"#,##0.00\)`,
+	8:  `"$"#,##0.00_);[Red]\("$"#,##0.00\)`,
+	9:  "0%",
+	10: "0.00%",
+	14: "yyyy-mm-dd",
+	15: "d-mmm-yy",
+	16: "d-mmm",
+	17: "mmm-yy",
+	18: "h:mm AM/PM",
+	19: "h:mm:ss AM/PM",
+	20: "h:mm",
+	21: "h:mm:ss",
+	22: "yyyy-mm-dd h:mm",
+	42: `_("$"* #,##0_);_("$"* \(#,##0\);_("$"* "-"_);_(@_)`,
+	44: `_("$"* #,##0.00_);_("$"* \(#,##0.00\);_("$"* "-"??_);_(@_)`,
+	45: "mm:ss",
+	46: "[h]:mm:ss",
+	47: "mm:ss.0",
+	// 中文等区域设置下的内置日期格式
+	27: `yyyy"年"m"月"`,
+	28: `m"月"d"日"`,
+	29: `m"月"d"日"`,
+	30: "m-d-yy",
+	31: `yyyy"年"m"月"d"日"`,
+	32: `h"时"mm"分"`,
+	33: `h"时"mm"分"ss"秒"`,
+	34: `上午/下午h"时"mm"分"`,
+	35: `上午/下午h"时"mm"分"ss"秒"`,
+	36: `yyyy"年"m"月"`,
+	50: `yyyy"年"m"月"`,
+	51: `m"月"d"日"`,
+	52: `yyyy"年"m"月"`,
+	53: `m"月"d"日"`,
+	54: `m"月"d"日"`,
+	55: `上午/下午h"时"mm"分"`,
+	56: `上午/下午h"时"mm"分"ss"秒"`,
+	57: `yyyy"年"m"月"`,
+	58: `m"月"d"日"`,
+}
+
+// currencySymbols 货币格式中常见的货币符号
+const currencySymbols = "$¥￥€£₩₹"
+
+// classifyNumberFormat 判断数字格式的类别
+func classifyNumberFormat(formatCode string) numberFormatKind {
+	if formatCode == "" || strings.EqualFold(formatCode, "General") {
+		return numberFormatGeneral
+	}
+
+	// 只看第一段格式（正数格式）
+	section := formatCode
+	if idx := strings.Index(section, ";"); idx >= 0 {
+		section = section[:idx]
+	}
+
+	// 去掉引号内的文本、转义字符以及颜色/区域设置等方括号内容（保留[h][m][s]经过时间）
+	var cleaned strings.Builder
+	hasCurrency := false
+	for i := 0; i < len(section); i++ {
+		switch c := section[i]; c {
+		case '"':
+			end := strings.IndexByte(section[i+1:], '"')
+			if end < 0 {
+				end = len(section) - i - 1
+			}
+			if strings.ContainsAny(section[i+1:i+1+end], currencySymbols) {
+				hasCurrency = true
+			}
+			i += end + 1
+		case '\\', '_', '*':
+			if c == '\\' {
+				if r, size := utf8.DecodeRuneInString(section[i+1:]); strings.ContainsRune(currencySymbols, r) {
+					hasCurrency = true
+					i += size - 1
+				}
+			}
+			i++
+		case '[':
+			end := strings.IndexByte(section[i:], ']')
+			if end < 0 {
+				i = len(section)
+				continue
+			}
+			inner := strings.ToLower(section[i+1 : i+end])
+			if strings.Trim(inner, "hms") == "" {
+				cleaned.WriteString(inner)
+			} else if strings.HasPrefix(inner, "$") && len(inner) > 1 && inner[1] != '-' {
+				// [$€-407]、[$USD] 等货币符号，[$-409] 只表示区域设置
+				hasCurrency = true
+			}
+			i += end
+		default:
+			cleaned.WriteByte(c)
+		}
+	}
+
+	code := strings.ToLower(cleaned.String())
+	hasTime := strings.ContainsAny(code, "hs")
+	hasDate := strings.ContainsAny(code, "yd") || (strings.Contains(code, "m") && !hasTime)
+	switch {
+	case hasDate && hasTime:
+		return numberFormatDateTime
+	case hasDate:
+		return numberFormatDate
+	case hasTime:
+		return numberFormatTime
+	case strings.Contains(code, "%"):
+		return numberFormatPercent
+	case hasCurrency || strings.ContainsAny(code, currencySymbols):
+		return numberFormatCurrency
+	}
+	return numberFormatGeneral
+}
+
+// serialDate 将Excel日期序列号按格式类别转换为日期值
+func serialDate(value float64, kind numberFormatKind, date1904 bool) (dateValue, bool) {
+	t, err := excelize.ExcelDateToTime(value, date1904)
+	if err != nil {
+		return dateValue{}, false
+	}
+	switch kind {
+	case numberFormatDateTime:
+		return dateValue{time: t, precision: precisionSecond}, true
+	case numberFormatDate:
+		return dateValue{time: t, precision: precisionDay}, true
+	case numberFormatTime:
+		return dateValue{time: t, precision: precisionTime}, true
+	}
+	return dateValue{}, false
+}
+
+// percentDecimals 计算百分比格式中的小数位数
+func percentDecimals(formatCode string) int {
+	idx := strings.Index(formatCode, ".")
+	if idx < 0 {
+		return 0
+	}
+	decimals := 0
+	for _, c := range formatCode[idx+1:] {
+		if c != '0' && c != '#' {
+			break
+		}
+		decimals++
+	}
+	return decimals
+}
+
+// formatGeneralNumber 按常规格式输出数值（最多15位有效数字）
+func formatGeneralNumber(value float64) string {
+	if rounded, err := strconv.ParseFloat(strconv.FormatFloat(value, 'g', 15, 64), 64); err == nil {
+		value = rounded
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
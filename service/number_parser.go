@@ -0,0 +1,70 @@
+package service
+
+import (
+	"encoding/json"
+	"file-url-parser/model"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// integerPattern 十进制整数
+	integerPattern = regexp.MustCompile(`^[+-]?\d+$`)
+	// decimalPattern 十进制数，可以带小数部分和指数部分（不接受十六进制、Inf、NaN等写法）
+	decimalPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+	// leadingZeroPattern 带前导零的数字，如编号 00123、邮编 010020
+	leadingZeroPattern = regexp.MustCompile(`^[+-]?0\d`)
+)
+
+// parseNumber 按数值转换模式解析单元格中的数值，不是数值时返回false
+func parseNumber(value string, mode string) (interface{}, bool) {
+	if mode == model.NumericModeString || !decimalPattern.MatchString(value) {
+		return nil, false
+	}
+
+	if mode == model.NumericModeFloat {
+		val, err := strconv.ParseFloat(value, 64)
+		return val, err == nil
+	}
+
+	// 带前导零的数字通常是编号，转换为数值会丢失前导零
+	if leadingZeroPattern.MatchString(value) {
+		return nil, false
+	}
+
+	if mode == model.NumericModeDecimal {
+		return json.Number(normalizeNumberLiteral(value)), true
+	}
+
+	// 整数保持精确：超出int64范围时按原样输出，避免身份证号、订单号等丢失精度
+	if integerPattern.MatchString(value) {
+		if val, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return val, true
+		}
+		return json.Number(normalizeNumberLiteral(value)), true
+	}
+
+	val, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		// 超出float64范围
+		return nil, false
+	}
+	return val, true
+}
+
+// normalizeNumberLiteral 将数值文本转换为合法的JSON数字：去掉正号，补全省略的整数部分，去掉末尾的小数点
+func normalizeNumberLiteral(value string) string {
+	value = strings.TrimPrefix(value, "+")
+	sign := ""
+	if strings.HasPrefix(value, "-") {
+		sign, value = "-", value[1:]
+	}
+	if strings.HasPrefix(value, ".") {
+		value = "0" + value
+	}
+	value = strings.Replace(value, ".e", "e", 1)
+	value = strings.Replace(value, ".E", "E", 1)
+	value = strings.TrimSuffix(value, ".")
+	return sign + value
+}
@@ -1,66 +1,163 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"file-url-parser/config"
 	"file-url-parser/model"
 	"file-url-parser/utils"
 	"strings"
+	"sync"
 )
 
+var (
+	downloaderOnce sync.Once
+	downloader     *utils.Downloader // 下载文件的共享客户端，第一次下载时按配置创建
+)
+
+// getDownloader 获取下载文件的共享客户端
+func getDownloader() *utils.Downloader {
+	downloaderOnce.Do(func() {
+		downloader = utils.NewDownloader(config.GetOutboundPolicy(), config.GetDownloadConfig())
+	})
+	return downloader
+}
+
 // ParseURLContent 解析URL内容
-func ParseURLContent(url string, offset int, limit int) (interface{}, error) {
+// ctx为请求的上下文，客户端断开连接时停止下载
+func ParseURLContent(ctx context.Context, url string, options model.ParseOptions) (interface{}, error) {
 	// 下载文件
-	data, fileInfo, err := utils.DownloadFile(url, config.GetMaxFileSize())
+	data, fileInfo, err := getDownloader().DownloadFile(ctx, url, options.MaxFileSize)
 	if err != nil {
 		return nil, err
 	}
 
+	// 根据文件内容、Content-Type和扩展名确定文件类型
+	if err := resolveFileType(data, fileInfo, options.FileType); err != nil {
+		return nil, err
+	}
+
 	// 检查文件类型是否支持
 	if !isSupportedFileType(fileInfo.FileType) {
+		if fileInfo.FileType == "" {
+			return nil, errors.New("无法确定文件类型，可通过file_type参数指定")
+		}
 		return nil, errors.New("不支持的文件类型: " + fileInfo.FileType)
 	}
+	fileTypeInfo := model.FileTypeInfo{
+		FileType:       strings.TrimPrefix(fileInfo.FileType, "."),
+		FileTypeSource: fileInfo.TypeSource,
+	}
 
-	// 保存临时文件
-	tempFilePath, err := utils.SaveTempFile(data, fileInfo.FileName)
+	// 保存临时文件，使用确定的文件类型作为扩展名
+	tempFilePath, err := utils.SaveTempFile(data, fileInfo.FileType)
 	if err != nil {
 		return nil, err
 	}
 	defer utils.CleanupTempFile(tempFilePath)
 
+	// 工作表相关的模式只支持Excel文件
+	if (options.ListSheets || options.AllSheets) && !fileInfo.IsExcel() {
+		return nil, errors.New("只有Excel文件支持工作表列表和多工作表解析")
+	}
+	if (options.ListTables || options.TableName != "" || options.NamedRange != "") && !fileInfo.IsExcel() {
+		return nil, errors.New("只有Excel文件支持表格和名称")
+	}
+
 	// 根据文件类型处理
 	switch {
+	case fileInfo.IsExcel() && options.ListSheets:
+		// 只列出工作表信息
+		sheets, err := ListExcelSheets(tempFilePath)
+		if err != nil {
+			return nil, err
+		}
+		return model.SheetListResponse{Sheets: sheets, FileTypeInfo: fileTypeInfo}, nil
+	case fileInfo.IsExcel() && options.ListTables:
+		// 只列出定义的表格和名称
+		tables, names, err := ListExcelTables(tempFilePath)
+		if err != nil {
+			return nil, err
+		}
+		return model.TableListResponse{Tables: tables, Names: names, FileTypeInfo: fileTypeInfo}, nil
+	case fileInfo.IsExcel() && options.AllSheets:
+		// 解析所有工作表
+		all, err := ParseExcelAllSheets(tempFilePath, options)
+		if err != nil {
+			return nil, err
+		}
+		response := model.MultiSheetResponse{
+			SheetNames:    all.SheetNames,
+			Sheets:        make(map[string]model.OrderedExcelResponse, len(all.Results)),
+			SkippedSheets: all.HiddenSheets,
+			FileTypeInfo:  fileTypeInfo,
+		}
+		for name, result := range all.Results {
+			response.Sheets[name] = toOrderedResponse(result)
+		}
+		// 解析失败的工作表只报告错误，不影响其他工作表
+		for name, err := range all.SheetErrors {
+			if response.SheetErrors == nil {
+				response.SheetErrors = make(map[string]string, len(all.SheetErrors))
+			}
+			response.SheetErrors[name] = err.Error()
+		}
+		return response, nil
 	case fileInfo.IsExcel():
 		// 解析Excel
-		result, err := ParseExcel(tempFilePath, offset, limit)
+		result, err := ParseExcel(tempFilePath, options)
 		if err != nil {
 			return nil, err
 		}
 		// 使用有序响应
-		return model.OrderedExcelResponse{
-			Data:           result.Data,
-			Headers:        result.Headers,
-			OriginalHeaders: result.OriginalHeaders,
-		}, nil
+		response := toOrderedResponse(result)
+		response.FileTypeInfo = fileTypeInfo
+		return response, nil
 	case fileInfo.IsCSV():
 		// 解析CSV
-		result, err := ParseCSV(tempFilePath, offset, limit)
+		result, err := ParseCSV(tempFilePath, options)
 		if err != nil {
 			return nil, err
 		}
 		// 使用有序响应
-		return model.OrderedExcelResponse{
-			Data:           result.Data,
-			Headers:        result.Headers,
-			OriginalHeaders: result.OriginalHeaders,
-		}, nil
+		response := toOrderedResponse(result)
+		response.FileTypeInfo = fileTypeInfo
+		return response, nil
 	default:
 		// 解析其他文件类型
-		content, err := ParseComplexFile(tempFilePath, fileInfo)
+		content, encodingName, err := ParseComplexFile(tempFilePath, fileInfo, options)
 		if err != nil {
 			return nil, err
 		}
-		return model.TextResponse{Content: content}, nil
+		return model.TextResponse{Content: content, Encoding: encodingName, FileTypeInfo: fileTypeInfo}, nil
+	}
+}
+
+// toOrderedResponse 将表格解析结果转换为按表头顺序输出的响应
+func toOrderedResponse(result ExcelParseResult) model.OrderedExcelResponse {
+	if result.Tables != nil {
+		tables := make([]model.OrderedExcelResponse, len(result.Tables))
+		for i, table := range result.Tables {
+			tables[i] = toOrderedResponse(table)
+		}
+		return model.OrderedExcelResponse{
+			Tables:         tables,
+			Encoding:       result.Encoding,
+			SkippedRows:    result.SkippedRows,
+			SkippedColumns: result.SkippedColumns,
+		}
+	}
+	return model.OrderedExcelResponse{
+		Data:            result.Data,
+		Headers:         result.Headers,
+		OriginalHeaders: result.OriginalHeaders,
+		Encoding:        result.Encoding,
+		HeaderTree:      result.HeaderTree,
+		TableOrigin:     result.TableOrigin,
+		Range:           result.Range,
+		Metadata:        result.Metadata,
+		SkippedRows:     result.SkippedRows,
+		SkippedColumns:  result.SkippedColumns,
 	}
 }
 
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// sheetCellReader 流式读取.xlsx工作表XML中单元格的原始值和样式ID
+// excelize的流式迭代器不返回单元格的样式ID，按样式ID查询需要加载整个工作表，因此直接解析工作表XML中c元素的s属性
+type sheetCellReader struct {
+	archive *zip.ReadCloser
+	part    io.ReadCloser
+	decoder *xml.Decoder
+	next    *sheetXMLRow // 已解析但尚未取出的行
+	lastRow int          // 最后解析的行的行号
+	done    bool         // 已读取到sheetData的结束
+}
+
+// sheetXMLRow 工作表XML中的一行
+type sheetXMLRow struct {
+	num   int            // 行号（从1开始）
+	cells []sheetXMLCell // 按列索引排列的单元格，没有单元格的列为零值
+}
+
+// sheetXMLCell 工作表XML中的单元格
+type sheetXMLCell struct {
+	raw    string // v元素的值，字符串单元格不保存（使用显示文本）
+	style  int    // 样式ID
+	isText bool   // 字符串单元格（共享字符串、内联字符串和公式的字符串结果）
+}
+
+// workbookRelType 包关系中指向工作簿的关系类型（后缀，兼容Strict格式的命名空间）
+const workbookRelType = "/officeDocument"
+
+// openSheetCellReader 打开.xlsx文件中指定工作表的XML，工作表按工作簿和关系文件中的路径查找
+func openSheetCellReader(filePath, sheetName string) (*sheetCellReader, error) {
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	partName, err := worksheetPartName(&archive.Reader, sheetName)
+	if err != nil {
+		archive.Close()
+		return nil, err
+	}
+	part, err := openZipPart(&archive.Reader, partName)
+	if err != nil {
+		archive.Close()
+		return nil, err
+	}
+	return &sheetCellReader{archive: archive, part: part, decoder: xml.NewDecoder(part)}, nil
+}
+
+// Close 关闭工作表XML和文件
+func (r *sheetCellReader) Close() error {
+	r.part.Close()
+	return r.archive.Close()
+}
+
+// read 读取第rowNum行（从1开始，必须递增）的原始值和样式ID
+// row为excelize迭代器读取的显示文本，字符串单元格的原始值使用显示文本，不再查找共享字符串
+// 显示文本中有值的单元格在工作表XML中找不到时，两个迭代器的行列已不一致，返回错误而不是错位地转换
+func (r *sheetCellReader) read(rowNum int, row []string) ([]string, []int, error) {
+	for !r.done && (r.next == nil || r.next.num < rowNum) {
+		next, err := r.decodeRow()
+		if err != nil {
+			return nil, nil, err
+		}
+		r.next = next
+	}
+	var cells []sheetXMLCell
+	if r.next != nil && r.next.num == rowNum {
+		cells = r.next.cells
+		r.next = nil
+	}
+	for col, text := range row {
+		if text != "" && (col >= len(cells) || (!cells[col].isText && cells[col].raw == "")) {
+			name, _ := excelize.CoordinatesToCellName(col+1, rowNum)
+			return nil, nil, errors.New("读取单元格原始值失败: 工作表XML中找不到单元格 " + name)
+		}
+	}
+	if cells == nil {
+		return nil, nil, nil
+	}
+	raw := make([]string, len(cells))
+	styles := make([]int, len(cells))
+	for col, cell := range cells {
+		raw[col], styles[col] = cell.raw, cell.style
+		if cell.isText {
+			raw[col] = ""
+			if col < len(row) {
+				raw[col] = row[col]
+			}
+		}
+	}
+	return raw, styles, nil
+}
+
+// decodeRow 解析下一个row元素，读取到sheetData的结束时返回nil
+func (r *sheetCellReader) decodeRow() (*sheetXMLRow, error) {
+	for {
+		token, err := r.decoder.Token()
+		if err == io.EOF {
+			r.done = true
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			if element.Name.Local != "row" {
+				continue
+			}
+			row := &sheetXMLRow{num: r.lastRow + 1}
+			if num, err := strconv.Atoi(xmlAttr(element, "r")); err == nil && num > 0 {
+				row.num = num
+			}
+			r.lastRow = row.num
+			if err := r.decodeCells(row); err != nil {
+				return nil, err
+			}
+			return row, nil
+		case xml.EndElement:
+			if element.Name.Local == "sheetData" {
+				r.done = true
+				return nil, nil
+			}
+		}
+	}
+}
+
+// decodeCells 解析row元素中的c元素，直到row元素结束
+func (r *sheetCellReader) decodeCells(row *sheetXMLRow) error {
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			if element.Name.Local != "c" {
+				continue
+			}
+			col := len(row.cells)
+			if ref := xmlAttr(element, "r"); ref != "" {
+				if c, _, err := excelize.CellNameToCoordinates(ref); err == nil {
+					col = c - 1
+				}
+			}
+			var value struct {
+				V string `xml:"v"`
+			}
+			if err := r.decoder.DecodeElement(&value, &element); err != nil {
+				return err
+			}
+			if col < len(row.cells) {
+				// 单元格顺序错乱的文件，忽略向前的单元格
+				continue
+			}
+			for len(row.cells) < col {
+				row.cells = append(row.cells, sheetXMLCell{})
+			}
+			style, _ := strconv.Atoi(xmlAttr(element, "s"))
+			switch xmlAttr(element, "t") {
+			case "s", "inlineStr", "str":
+				row.cells = append(row.cells, sheetXMLCell{style: style, isText: true})
+			default:
+				row.cells = append(row.cells, sheetXMLCell{raw: value.V, style: style})
+			}
+		case xml.EndElement:
+			if element.Name.Local == "row" {
+				return nil
+			}
+		}
+	}
+}
+
+// xmlAttr 返回元素的属性值（按不带命名空间的名称查找），没有时返回空字符串
+func xmlAttr(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// xmlRelationships 包关系文件（.rels）
+type xmlRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Type   string `xml:"Type,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xmlWorkbookSheets 工作簿XML中的工作表列表
+type xmlWorkbookSheets struct {
+	Sheets []struct {
+		Name  string     `xml:"name,attr"`
+		Attrs []xml.Attr `xml:",any,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// worksheetPartName 根据包关系、工作簿和工作簿的关系文件查找工作表XML在压缩包中的路径
+func worksheetPartName(archive *zip.Reader, sheetName string) (string, error) {
+	workbookPart := "xl/workbook.xml"
+	var rootRels xmlRelationships
+	if err := decodeZipPart(archive, "_rels/.rels", &rootRels); err == nil {
+		for _, rel := range rootRels.Relationships {
+			if strings.HasSuffix(rel.Type, workbookRelType) {
+				workbookPart = resolvePartName("", rel.Target)
+				break
+			}
+		}
+	}
+
+	var workbookXML xmlWorkbookSheets
+	if err := decodeZipPart(archive, workbookPart, &workbookXML); err != nil {
+		return "", err
+	}
+	relID := ""
+	for _, sheet := range workbookXML.Sheets {
+		if sheet.Name != sheetName {
+			continue
+		}
+		for _, attr := range sheet.Attrs {
+			if attr.Name.Local == "id" {
+				relID = attr.Value
+			}
+		}
+	}
+	if relID == "" {
+		return "", errors.New("工作表不存在: " + sheetName)
+	}
+
+	dir, file := path.Split(workbookPart)
+	var workbookRels xmlRelationships
+	if err := decodeZipPart(archive, dir+"_rels/"+file+".rels", &workbookRels); err != nil {
+		return "", err
+	}
+	for _, rel := range workbookRels.Relationships {
+		if rel.ID == relID {
+			return resolvePartName(dir, rel.Target), nil
+		}
+	}
+	return "", errors.New("找不到工作表的数据: " + sheetName)
+}
+
+// resolvePartName 将关系的目标转换为压缩包中的路径，目标可以是绝对路径或相对于dir的路径
+func resolvePartName(dir, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(path.Clean(target), "/")
+	}
+	return strings.TrimPrefix(path.Clean("/"+dir+target), "/")
+}
+
+// openZipPart 打开压缩包中的文件（不区分大小写）
+func openZipPart(archive *zip.Reader, name string) (io.ReadCloser, error) {
+	for _, file := range archive.File {
+		if strings.EqualFold(strings.ReplaceAll(file.Name, "\\", "/"), name) {
+			return file.Open()
+		}
+	}
+	return nil, errors.New("文件中缺少 " + name)
+}
+
+// decodeZipPart 解析压缩包中的XML文件
+func decodeZipPart(archive *zip.Reader, name string, v interface{}) error {
+	part, err := openZipPart(archive, name)
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+	return xml.NewDecoder(part).Decode(v)
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"archive/zip"
+	"file-url-parser/model"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// sparseSheetXML 行之间有空行、有的行和单元格缺少r属性的工作表（缺少r属性的行为上一行的下一行），B3:B4为合并单元格
+const sparseSheetXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="inlineStr"><is><t>name</t></is></c><c r="B1" t="inlineStr"><is><t>date</t></is></c><c r="C1" t="inlineStr"><is><t>qty</t></is></c></row>
+<row r="3"><c r="A3" t="inlineStr"><is><t>a</t></is></c><c r="B3" s="1"><v>45292</v></c><c r="C3"><v>1</v></c></row>
+<row><c r="A4" t="inlineStr"><is><t>b</t></is></c><c r="C4" s="2"><v>0.25</v></c></row>
+<row r="7"><c t="inlineStr"><is><t>c</t></is></c><c s="1"><v>45294</v></c><c t="b"><v>1</v></c></row>
+</sheetData><mergeCells count="1"><mergeCell ref="B3:B4"/></mergeCells></worksheet>`
+
+// writeSparseWorkbook 生成只包含sparseSheetXML工作表的.xlsx文件，样式1为日期格式，样式2为百分比格式
+func writeSparseWorkbook(t *testing.T) string {
+	t.Helper()
+	parts := map[string]string{
+		"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`,
+		"_rels/.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`,
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`,
+		"xl/styles.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<fonts count="1"><font/></fonts><fills count="1"><fill/></fills><borders count="1"><border/></borders>
+<cellStyleXfs count="1"><xf numFmtId="0"/></cellStyleXfs>
+<cellXfs count="3"><xf numFmtId="0" xfId="0"/><xf numFmtId="14" xfId="0" applyNumberFormat="1"/><xf numFmtId="9" xfId="0" applyNumberFormat="1"/></cellXfs>
+</styleSheet>`,
+		"xl/worksheets/sheet1.xml": sparseSheetXML,
+	}
+
+	filePath := filepath.Join(t.TempDir(), "sparse.xlsx")
+	file, err := os.Create(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	writer := zip.NewWriter(file)
+	for name, content := range parts {
+		part, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := part.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return filePath
+}
+
+// TestSheetCellReaderAlignment 原始值和样式ID与excelize.GetRows的显示文本按行列对齐
+func TestSheetCellReaderAlignment(t *testing.T) {
+	filePath := writeSparseWorkbook(t)
+	f, err := excelize.OpenFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	allRows, err := f.GetRows("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cellXML, err := openSheetCellReader(filePath, "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cellXML.Close()
+
+	wantRaw := map[int][]string{
+		1: {"name", "date", "qty"},
+		3: {"a", "45292", "1"},
+		4: {"b", "", "0.25"},
+		7: {"c", "45294", "1"},
+	}
+	wantStyles := map[int][]int{
+		1: {0, 0, 0},
+		3: {0, 1, 0},
+		4: {0, 0, 2},
+		7: {0, 1, 0},
+	}
+	for i, row := range allRows {
+		rowNum := i + 1
+		raw, styles, err := cellXML.read(rowNum, row)
+		if err != nil {
+			t.Fatalf("第%d行: %v", rowNum, err)
+		}
+		if !reflect.DeepEqual(raw, wantRaw[rowNum]) || !reflect.DeepEqual(styles, wantStyles[rowNum]) {
+			t.Errorf("第%d行（显示文本 %q）的原始值为 %q、样式为 %v，应为 %q、%v",
+				rowNum, row, raw, styles, wantRaw[rowNum], wantStyles[rowNum])
+		}
+		for col, text := range row {
+			if text != "" && col >= len(raw) {
+				t.Errorf("第%d行第%d列有显示文本 %q 但没有原始值", rowNum, col+1, text)
+			}
+		}
+	}
+}
+
+// TestSheetCellReaderMismatch 显示文本中有值的单元格在工作表XML中找不到时返回错误，而不是错位
+func TestSheetCellReaderMismatch(t *testing.T) {
+	cellXML, err := openSheetCellReader(writeSparseWorkbook(t), "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cellXML.Close()
+
+	if _, _, err := cellXML.read(1, []string{"name", "date", "qty", "extra"}); err == nil {
+		t.Error("第1行多出的单元格应返回错误")
+	}
+	// 第2行在XML中不存在
+	if _, _, err := cellXML.read(2, []string{"x"}); err == nil {
+		t.Error("XML中不存在的行有显示文本时应返回错误")
+	}
+}
+
+// TestParseExcelSparseRows 有空行和缺少r属性的行时，类型化的值与显示文本对应到同一个单元格
+func TestParseExcelSparseRows(t *testing.T) {
+	filePath := writeSparseWorkbook(t)
+	options := model.ParseOptions{
+		UseHeaderAsKey: true,
+		MaxAllowedRows: -1,
+		Limit:          -1,
+		NumericMode:    model.NumericModeAuto,
+		Tables:         model.TablesFirst,
+		HeaderRows:     1,
+		Table:          model.TableRange{HeaderRow: 1, StartCol: 1}, // 表头之后有空行，指定表头所在的行
+		IncludeHidden:  model.HiddenOptions{Rows: true, Columns: true, Sheets: true},
+	}
+	for _, merged := range []string{model.MergedCellsNone, model.MergedCellsFillDown} {
+		for _, cellValues := range []string{model.CellValuesTyped, model.CellValuesFormatted} {
+			options.MergedCells, options.CellValues = merged, cellValues
+			result, err := ParseExcel(filePath, options)
+			if err != nil {
+				t.Fatalf("%s/%s: %v", merged, cellValues, err)
+			}
+			values := make([][]interface{}, len(result.Data))
+			for i, row := range result.Data {
+				values[i] = []interface{}{row["name"], row["date"], row["qty"]}
+			}
+			// 按列向下填充时第4行的日期来自合并区域左上角的B3
+			date := map[string]interface{}{model.MergedCellsNone: nil, model.MergedCellsFillDown: "2024-01-01"}[merged]
+			want := [][]interface{}{
+				{"a", "2024-01-01", int64(1)},
+				{"b", date, 0.25},
+				{"c", "2024-01-03", true},
+			}
+			if cellValues == model.CellValuesFormatted {
+				date = map[string]interface{}{model.MergedCellsNone: nil, model.MergedCellsFillDown: "01-01-24"}[merged]
+				want = [][]interface{}{
+					{"a", "01-01-24", int64(1)},
+					{"b", date, "25%"},
+					{"c", "01-03-24", "TRUE"},
+				}
+			}
+			if !reflect.DeepEqual(values, want) {
+				t.Errorf("%s/%s: 解析结果为 %#v，应为 %#v", merged, cellValues, values, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,485 @@
+package service
+
+import (
+	"errors"
+	"file-url-parser/model"
+	"file-url-parser/utils"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// headerLookaheadRows 查找表头时最多预读的行数
+const headerLookaheadRows = 100
+
+// thousandsPattern 使用逗号作为千分位分隔符的数字
+var thousandsPattern = regexp.MustCompile(`^[+-]?\d{1,3}(,\d{3})+(\.\d+)?$`)
+
+// rowReader 表格行迭代器，按顺序逐行读取表格数据
+type rowReader interface {
+	// Next 读取下一行的单元格内容，没有更多数据时返回 io.EOF
+	Next() ([]string, error)
+	// Skip 跳过下一行而不解析单元格内容，没有更多数据时返回 io.EOF
+	Skip() error
+}
+
+// typedCell 单元格的原始值和按单元格类型转换后的值
+type typedCell struct {
+	raw      string              // 单元格中保存的原始值
+	value    interface{}         // 类型化的值：bool、dateValue或excelNumber，nil表示按显示文本推断
+	formula  string              // 公式文本（以"="开头），只在formulas为text或both时读取
+	metadata *model.CellMetadata // 超链接、批注等附加信息，只在开启cell_metadata时读取
+}
+
+// excelNumber Excel数值单元格按常规格式输出的文本，转换时按numeric_mode处理
+type excelNumber string
+
+// typedRowReader 能够提供类型化单元格值的行读取器（Excel工作表）
+type typedRowReader interface {
+	rowReader
+	// CellValues 返回第rowNum行（从0开始，按读取顺序计）各单元格的类型化值，没有时返回nil
+	CellValues(rowNum int) []typedCell
+}
+
+// bufferedRowReader 先返回预读的行，再继续从底层迭代器读取
+type bufferedRowReader struct {
+	buffered [][]string
+	reader   rowReader
+}
+
+// Next 读取下一行
+func (r *bufferedRowReader) Next() ([]string, error) {
+	if len(r.buffered) > 0 {
+		row := r.buffered[0]
+		r.buffered = r.buffered[1:]
+		return row, nil
+	}
+	return r.reader.Next()
+}
+
+// Skip 跳过下一行
+func (r *bufferedRowReader) Skip() error {
+	if len(r.buffered) > 0 {
+		r.buffered = r.buffered[1:]
+		return nil
+	}
+	return r.reader.Skip()
+}
+
+// sliceRowReader 基于已读取到内存中的行数据的行读取器
+type sliceRowReader struct {
+	rows [][]string
+}
+
+// Next 读取下一行
+func (r *sliceRowReader) Next() ([]string, error) {
+	if len(r.rows) == 0 {
+		return nil, io.EOF
+	}
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	return row, nil
+}
+
+// Skip 跳过下一行
+func (r *sliceRowReader) Skip() error {
+	_, err := r.Next()
+	return err
+}
+
+// typedSliceRowReader 基于已读取到内存中的行数据的行读取器，同时提供类型化的单元格值
+type typedSliceRowReader struct {
+	sliceRowReader
+	cells [][]typedCell
+}
+
+// CellValues 返回指定行的类型化单元格值
+func (r *typedSliceRowReader) CellValues(rowNum int) []typedCell {
+	if rowNum < 0 || rowNum >= len(r.cells) {
+		return nil
+	}
+	return r.cells[rowNum]
+}
+
+// parseTableRows 从行迭代器中解析表格数据
+// 表头通过有限的预读行检测，偏移量之前的行直接跳过，读取完分页数据后即停止，
+// 整个过程不会缓存全部数据行
+func parseTableRows(reader rowReader, options model.ParseOptions) (ExcelParseResult, error) {
+	// 从原始读取器获取类型化的单元格值和公式，跳过了隐藏行列时同时获取工作表中的位置
+	typedReader, _ := reader.(typedRowReader)
+	positions, _ := reader.(sheetPositionReader)
+
+	// 使用指定的表格范围，或预读若干行查找表格数据的实际起始位置
+	location, err := locateTable(reader, options.Table)
+	if err != nil {
+		return ExcelParseResult{}, err
+	}
+	if location.headerIdx == -1 {
+		// 找不到有效的表格数据
+		return ExcelParseResult{Data: []map[string]interface{}{}}, nil
+	}
+	reader = location.reader
+	headerIdx, startCol := location.headerIdx, location.startCol
+
+	// 确定表头行数（多行表头）
+	headerRows, startCol, lookahead, err := detectHeaderRows(reader, location.lookahead, headerIdx, startCol, options)
+	if err != nil {
+		return ExcelParseResult{}, err
+	}
+
+	// 使用找到的表头行
+	originalHeaders := []string{}
+	var paths [][]string
+	if headerRows == 1 {
+		if headerRow := lookahead[headerIdx]; startCol < len(headerRow) {
+			originalHeaders = headerRow[startCol:]
+		}
+	} else {
+		// 多行表头按列组合为复合表头，如 "2024 Q1/收入"
+		paths = headerPaths(lookahead[headerIdx:headerIdx+headerRows], startCol)
+		originalHeaders = make([]string, len(paths))
+		for i, path := range paths {
+			originalHeaders[i] = strings.Join(path, options.HeaderSeparator)
+		}
+	}
+	headers := buildHeaders(originalHeaders, options)
+	var headerTree []model.HeaderNode
+	if paths != nil {
+		headerTree = buildHeaderTree(paths, headers)
+	}
+
+	converter := newRowConverter(headers, originalHeaders, options)
+
+	// 表头之后的预读行作为数据行优先返回
+	dataReader := &bufferedRowReader{
+		buffered: lookahead[headerIdx+headerRows:],
+		reader:   reader,
+	}
+
+	// 处理无限制的情况 (maxAllowedRows = -1)
+	maxAllowedRows := options.MaxAllowedRows
+	hasRowLimit := maxAllowedRows != -1
+
+	// 计算分页范围（按数据行索引，从表头下一行开始计算偏移）
+	startIndex := options.Offset
+	endIndex := -1 // -1 表示读取到末尾
+	if options.Limit > 0 {
+		endIndex = startIndex + options.Limit
+	}
+
+	var result []map[string]interface{}
+	var metadata []map[string]model.CellMetadata
+	withMetadata := options.CellMetadata != "" && options.CellMetadata != model.CellMetadataNone
+	consumedRows := 0 // 已读取或跳过的数据行数
+	dataRows := 0     // 截至最后一个非空行的数据行数
+	for rowIdx := 0; ; rowIdx++ {
+		inPage := rowIdx >= startIndex && (endIndex == -1 || rowIdx < endIndex)
+		// 超过最大行数的位置需要检查是否还有数据
+		needCheck := hasRowLimit && rowIdx >= maxAllowedRows
+
+		if !inPage && !needCheck {
+			if rowIdx >= startIndex && !hasRowLimit {
+				// 分页数据已读取完毕，且无需统计总行数
+				break
+			}
+			// 不在分页范围内的行直接跳过
+			if err := dataReader.Skip(); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return ExcelParseResult{}, err
+			}
+			consumedRows++
+			continue
+		}
+
+		row, err := dataReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return ExcelParseResult{}, err
+		}
+		consumedRows++
+		if hasAnyValue(row) {
+			dataRows = rowIdx + 1
+		}
+
+		// 如果有行数限制且数据量超过限制
+		if needCheck && dataRows > maxAllowedRows {
+			return ExcelParseResult{}, errors.New("数据行数超过限制，最多允许 " + strconv.Itoa(maxAllowedRows) + " 行数据")
+		}
+
+		if !inPage {
+			continue
+		}
+
+		var cells []typedCell
+		if typedReader != nil {
+			// 数据行在读取器中的行号：跳过的行、表头之后的第rowIdx行
+			if cells = typedReader.CellValues(location.rowOffset + headerIdx + headerRows + rowIdx); len(cells) > startCol {
+				cells = cells[startCol:]
+			} else {
+				cells = nil
+			}
+		}
+
+		// 跳过空行（只有没有缓存结果的公式时不是空行）
+		if (len(row) <= startCol || isEmptyRow(row, startCol)) && !hasFormula(cells) {
+			continue
+		}
+		if len(row) <= startCol {
+			row = append(row, make([]string, startCol-len(row)+1)...)
+		}
+
+		// 只处理从起始列开始的数据
+		if item := converter.convertRow(row[startCol:], cells); len(item) > 0 {
+			result = append(result, item)
+			if withMetadata {
+				metadata = append(metadata, converter.convertMetadata(cells))
+			}
+		}
+	}
+
+	if consumedRows == 0 {
+		// 没有数据行，只有表头
+		return ExcelParseResult{
+			Data:            []map[string]interface{}{},
+			Headers:         []string{},
+			OriginalHeaders: []string{},
+		}, nil
+	}
+	if result == nil {
+		// 偏移量超出范围，返回空数据
+		result = []map[string]interface{}{}
+	}
+	if withMetadata && metadata == nil {
+		metadata = []map[string]model.CellMetadata{}
+	}
+
+	origin := location.origin()
+	sheetOrigin(origin, positions)
+	return ExcelParseResult{
+		Data:            result,
+		Headers:         headers,
+		OriginalHeaders: originalHeaders,
+		HeaderTree:      headerTree,
+		TableOrigin:     origin,
+		Metadata:        metadata,
+	}, nil
+}
+
+// detectTableStart 预读有限的行数查找表格数据的实际起始位置，只检查minCol及右侧的单元格
+// 返回表头行在预读行中的索引、起始列索引和预读的行，找不到时表头行索引为-1
+func detectTableStart(reader rowReader, minCol int) (int, int, [][]string, error) {
+	lookahead := make([][]string, 0, 8)
+	for len(lookahead) < headerLookaheadRows {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return -1, -1, nil, err
+		}
+		lookahead = append(lookahead, row)
+
+		// 检查上一行是否为表头行
+		if n := len(lookahead); n >= 2 {
+			if col := headerStartCol(lookahead[n-2], lookahead[n-1], minCol); col != -1 {
+				return n - 2, col, lookahead, nil
+			}
+		}
+	}
+
+	// 至少需要两行数据（表头+数据）
+	if len(lookahead) < 2 {
+		return -1, -1, lookahead, nil
+	}
+
+	// 如果没有找到符合条件的表头行，使用第一行作为表头（如果有数据）
+	if len(lookahead[0]) > 0 {
+		return 0, minCol, lookahead, nil
+	}
+
+	return -1, -1, lookahead, nil
+}
+
+// headerStartCol 查找行中从minCol开始第一个下方也有内容的非空单元格，作为表头的起始列
+// 找不到时返回-1
+func headerStartCol(row, nextRow []string, minCol int) int {
+	for colIdx, cell := range row {
+		if colIdx < minCol || strings.TrimSpace(cell) == "" {
+			continue
+		}
+		// 找到非空单元格，检查下一行是否也有内容（表示这是表头行）
+		if len(nextRow) > colIdx && strings.TrimSpace(nextRow[colIdx]) != "" {
+			// 验证这是否是一个有效的表头行（检查是否有足够的连续非空单元格）
+			if countConsecutiveNonEmptyCells(row, colIdx) >= 1 {
+				return colIdx
+			}
+		}
+	}
+	return -1
+}
+
+// rowConverter 按表头和解析选项将行数据转换为键值对
+type rowConverter struct {
+	headers   []string
+	splitList []bool // 各列是否拆分列表
+	options   model.ParseOptions
+}
+
+// newRowConverter 创建行转换器，根据列表拆分参数确定需要拆分的列
+func newRowConverter(headers []string, originalHeaders []string, options model.ParseOptions) *rowConverter {
+	splitList := make([]bool, len(headers))
+	for i := range headers {
+		if !options.List.Enabled {
+			continue
+		}
+		if len(options.List.Columns) == 0 {
+			splitList[i] = true
+			continue
+		}
+		// 指定列时按表头名称或键名匹配
+		for _, column := range options.List.Columns {
+			if column == headers[i] || (i < len(originalHeaders) && column == strings.TrimSpace(originalHeaders[i])) {
+				splitList[i] = true
+				break
+			}
+		}
+	}
+	return &rowConverter{headers: headers, splitList: splitList, options: options}
+}
+
+// convertRow 将一行数据按表头转换为键值对，空单元格不输出
+// cells为对应的类型化单元格值，为nil时按显示文本推断类型
+func (c *rowConverter) convertRow(rowData []string, cells []typedCell) map[string]interface{} {
+	item := make(map[string]interface{})
+
+	// 确保行数据与表头匹配
+	for j := 0; j < len(c.headers) && (j < len(rowData) || j < len(cells)); j++ {
+		var cellValue string
+		if j < len(rowData) {
+			cellValue = rowData[j]
+		}
+
+		var cell typedCell
+		if j < len(cells) {
+			cell = cells[j]
+		}
+
+		// 跳过空单元格（没有缓存结果的公式除外）
+		if cellValue == "" && cell.formula == "" {
+			continue
+		}
+		item[c.headers[j]] = c.convertCell(j, cellValue, cell)
+	}
+
+	return item
+}
+
+// convertCell 转换单个单元格：有类型化的值时优先使用，否则按显示文本推断类型
+// 公式单元格按formulas参数输出公式文本或包含公式的对象
+func (c *rowConverter) convertCell(col int, text string, cell typedCell) interface{} {
+	var value interface{}
+	switch {
+	case cell.formula != "" && c.options.Formulas == model.FormulasText:
+		value = cell.formula
+	case text != "":
+		if value = typedCellValue(text, cell, c.options); value == nil {
+			value = convertCellValue(text, c.splitList[col], c.options)
+		}
+	}
+
+	if c.options.CellValues == model.CellValuesBoth {
+		raw := cell.raw
+		if raw == "" {
+			raw = text
+		}
+		return model.CellValue{Value: value, Raw: raw, Formatted: text, Formula: cell.formula}
+	}
+	if cell.formula != "" {
+		return formulaCellValue(value, cell.formula, c.options)
+	}
+	return value
+}
+
+// convertMetadata 按表头输出一行中各单元格的附加信息，没有附加信息的单元格不输出
+func (c *rowConverter) convertMetadata(cells []typedCell) map[string]model.CellMetadata {
+	item := make(map[string]model.CellMetadata)
+	for j := 0; j < len(c.headers) && j < len(cells); j++ {
+		if cells[j].metadata != nil {
+			item[c.headers[j]] = *cells[j].metadata
+		}
+	}
+	return item
+}
+
+// typedCellValue 根据单元格类型得到输出的值，返回nil时按显示文本推断类型
+func typedCellValue(text string, cell typedCell, options model.ParseOptions) interface{} {
+	if options.CellValues == model.CellValuesFormatted {
+		return nil
+	}
+
+	var number excelNumber
+	switch value := cell.value.(type) {
+	case excelNumber:
+		number = value
+	case dateValue:
+		return value.format(options.Date)
+	default:
+		// 布尔值或没有类型信息
+		return value
+	}
+
+	// 显示文本带前导零（如格式为 00000 的编号）或不转换数值时，使用显示文本
+	if options.NumericMode == model.NumericModeString || leadingZeroPattern.MatchString(text) {
+		return nil
+	}
+	if value, ok := parseNumber(string(number), options.NumericMode); ok {
+		return value
+	}
+	return nil
+}
+
+// convertCellValue 转换单元格的值：数值、日期、分隔符分隔的列表或字符串
+func convertCellValue(cellValue string, splitList bool, options model.ParseOptions) interface{} {
+	// 尝试解析数值
+	if val, ok := parseNumber(cellValue, options.NumericMode); ok {
+		return val
+	}
+
+	// 识别日期，整个单元格都能解析为日期时才转换
+	if formattedDate, ok := recognizeDate(cellValue, options.Date); ok {
+		return formattedDate
+	}
+
+	// 处理分隔符分隔的内容，千分位数字（如 1,234.56）不拆分
+	if splitList && utils.ContainsAnySeparator(cellValue, options.List.Separators) && !thousandsPattern.MatchString(cellValue) {
+		return utils.SplitList(cellValue, options.List.Separators, options.List.DropEmpty)
+	}
+
+	// 默认为字符串
+	return cellValue
+}
+
+// hasFormula 检查单元格中是否有公式
+func hasFormula(cells []typedCell) bool {
+	for _, cell := range cells {
+		if cell.formula != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAnyValue 检查行中是否有非空单元格
+func hasAnyValue(row []string) bool {
+	for _, cell := range row {
+		if cell != "" {
+			return true
+		}
+	}
+	return false
+}
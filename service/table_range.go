@@ -0,0 +1,173 @@
+package service
+
+import (
+	"errors"
+	"file-url-parser/model"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ParseCellRange 解析A1格式的表格范围，如 "B4:K200"（第4行为表头，数据读取到第200行、K列）
+// 只有起始单元格（如 "B4"）时不限制结束行和结束列
+func ParseCellRange(value string) (model.TableRange, error) {
+	start, end, hasEnd := strings.Cut(strings.ToUpper(strings.TrimSpace(value)), ":")
+	startCol, startRow, err := excelize.CellNameToCoordinates(start)
+	if err != nil {
+		return model.TableRange{}, errors.New("range参数格式无效，应为A1格式（如 B4:K200）: " + value)
+	}
+	tableRange := model.TableRange{HeaderRow: startRow, StartCol: startCol}
+	if !hasEnd {
+		return tableRange, nil
+	}
+
+	endCol, endRow, err := excelize.CellNameToCoordinates(end)
+	if err != nil {
+		return model.TableRange{}, errors.New("range参数格式无效，应为A1格式（如 B4:K200）: " + value)
+	}
+	if endRow < startRow || endCol < startCol {
+		return model.TableRange{}, errors.New("range参数的结束单元格必须在起始单元格的右下方: " + value)
+	}
+	tableRange.EndRow = endRow
+	tableRange.EndCol = endCol
+	return tableRange, nil
+}
+
+// ParseColumnName 解析列名（如 "B"、"AK"），返回从1开始的列号
+func ParseColumnName(name string) (int, error) {
+	col, err := excelize.ColumnNameToNumber(strings.TrimSpace(name))
+	if err != nil {
+		return 0, errors.New("start_col参数必须是列名（如 B）或从1开始的列号")
+	}
+	return col, nil
+}
+
+// tableLocation 表格在工作表中的位置
+type tableLocation struct {
+	rowOffset int        // 预读之前跳过的行数
+	headerIdx int        // 表头行在预读行中的索引，-1表示找不到表格
+	startCol  int        // 起始列索引（从0开始）
+	lookahead [][]string // 预读的行
+	detected  bool       // 表头行和起始列是否为自动检测
+	reader    rowReader  // 继续读取数据行的读取器（已按表格范围限制）
+}
+
+// locateTable 确定表格的表头行和起始列
+// 指定了表头行时跳过之前的行，不再自动检测；指定了起始列时只在该列及右侧检测表头
+func locateTable(reader rowReader, tableRange model.TableRange) (tableLocation, error) {
+	if tableRange.HeaderRow == 0 {
+		reader = newRangeRowReader(reader, tableRange)
+		minCol := max(tableRange.StartCol-1, 0)
+		headerIdx, startCol, lookahead, err := detectTableStart(reader, minCol)
+		if err != nil {
+			return tableLocation{}, err
+		}
+		location := tableLocation{headerIdx: headerIdx, startCol: startCol, lookahead: lookahead, detected: true, reader: reader}
+		if tableRange.StartCol > 0 && headerIdx != -1 {
+			location.startCol = tableRange.StartCol - 1
+		}
+		return location, nil
+	}
+
+	// 跳过表头之前的行
+	location := tableLocation{rowOffset: tableRange.HeaderRow - 1, headerIdx: -1}
+	for i := 0; i < location.rowOffset; i++ {
+		if err := reader.Skip(); err != nil {
+			if err == io.EOF {
+				return location, nil
+			}
+			return tableLocation{}, err
+		}
+	}
+	reader = newRangeRowReader(reader, tableRange)
+	location.reader = reader
+
+	header, err := reader.Next()
+	if err == io.EOF {
+		return location, nil
+	}
+	if err != nil {
+		return tableLocation{}, err
+	}
+	location.headerIdx = 0
+	location.lookahead = [][]string{header}
+
+	if tableRange.StartCol > 0 {
+		location.startCol = tableRange.StartCol - 1
+		return location, nil
+	}
+
+	// 未指定起始列时，按表头行和下一行确定起始列
+	next, err := reader.Next()
+	if err != nil && err != io.EOF {
+		return tableLocation{}, err
+	}
+	if err == nil {
+		location.lookahead = append(location.lookahead, next)
+	}
+	location.startCol = headerStartCol(header, next, 0)
+	if location.startCol == -1 {
+		location.startCol = max(firstNonEmptyCol(header), 0)
+	}
+	return location, nil
+}
+
+// origin 返回表格的起始位置（表头行的第一个单元格）
+func (l tableLocation) origin() *model.TableOrigin {
+	row := l.rowOffset + l.headerIdx + 1
+	cell, _ := excelize.CoordinatesToCellName(l.startCol+1, row)
+	return &model.TableOrigin{Cell: cell, HeaderRow: row, StartCol: l.startCol + 1, Detected: l.detected}
+}
+
+// rangeRowReader 只读取表格范围内的行和列
+type rangeRowReader struct {
+	reader    rowReader
+	remaining int // 还可以读取的行数，-1表示不限制
+	endCol    int // 读取的列数，0表示不限制
+}
+
+// newRangeRowReader 创建按表格范围限制读取的行读取器，没有指定结束行和结束列时直接返回原读取器
+func newRangeRowReader(reader rowReader, tableRange model.TableRange) rowReader {
+	if tableRange.EndRow == 0 && tableRange.EndCol == 0 {
+		return reader
+	}
+	rangeReader := &rangeRowReader{reader: reader, remaining: -1, endCol: tableRange.EndCol}
+	if tableRange.EndRow > 0 {
+		// 从表头行开始计算
+		rangeReader.remaining = tableRange.EndRow - max(tableRange.HeaderRow, 1) + 1
+	}
+	return rangeReader
+}
+
+// Next 读取下一行，超出结束列的单元格会被去掉
+func (r *rangeRowReader) Next() ([]string, error) {
+	if r.remaining == 0 {
+		return nil, io.EOF
+	}
+	row, err := r.reader.Next()
+	if err != nil {
+		return nil, err
+	}
+	if r.remaining > 0 {
+		r.remaining--
+	}
+	if r.endCol > 0 && len(row) > r.endCol {
+		row = row[:r.endCol]
+	}
+	return row, nil
+}
+
+// Skip 跳过下一行
+func (r *rangeRowReader) Skip() error {
+	if r.remaining == 0 {
+		return io.EOF
+	}
+	if err := r.reader.Skip(); err != nil {
+		return err
+	}
+	if r.remaining > 0 {
+		r.remaining--
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"file-url-parser/model"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// tableBlock 工作表中的矩形表格区域（行列索引从0开始，包含结束行列）
+type tableBlock struct {
+	top, bottom int
+	left, right int
+}
+
+// rangeName 返回区域的A1格式名称，如 "B4:K20"，positions不为nil时按工作表中的位置（跳过了隐藏行列）计算
+func (b tableBlock) rangeName(positions sheetPositionReader) string {
+	top, bottom, left, right := b.top+1, b.bottom+1, b.left+1, b.right+1
+	if positions != nil {
+		top, bottom = positions.SheetRow(b.top), positions.SheetRow(b.bottom)
+		left, right = positions.SheetCol(b.left), positions.SheetCol(b.right)
+	}
+	start, _ := excelize.CoordinatesToCellName(left, top)
+	end, _ := excelize.CoordinatesToCellName(right, bottom)
+	return start + ":" + end
+}
+
+// parseTables 按tables参数解析第一个表格，或者工作表中的所有表格
+func parseTables(reader rowReader, options model.ParseOptions) (ExcelParseResult, error) {
+	if options.Tables != model.TablesAll {
+		return parseTableRows(reader, options)
+	}
+
+	// 分割表格需要整个工作表的数据
+	positions, _ := reader.(sheetPositionReader)
+	rows, cells, err := readAllRows(reader)
+	if err != nil {
+		return ExcelParseResult{}, err
+	}
+
+	tables := []ExcelParseResult{}
+	for _, block := range segmentTables(rows) {
+		if block.bottom == block.top {
+			// 只有一行的区域是标题或备注，不是表格
+			continue
+		}
+
+		blockRows := make([][]string, 0, block.bottom-block.top+1)
+		var blockCells [][]typedCell
+		if cells != nil {
+			blockCells = make([][]typedCell, 0, block.bottom-block.top+1)
+		}
+		for row := block.top; row <= block.bottom; row++ {
+			blockRows = append(blockRows, sliceColumns(rows[row], block.left, block.right))
+			if cells != nil {
+				blockCells = append(blockCells, sliceColumns(cells[row], block.left, block.right))
+			}
+		}
+
+		result, err := parseTableRows(&typedSliceRowReader{sliceRowReader: sliceRowReader{rows: blockRows}, cells: blockCells}, options)
+		if err != nil {
+			return ExcelParseResult{}, err
+		}
+		if result.Headers == nil {
+			continue
+		}
+		result.Range = block.rangeName(positions)
+		if origin := result.TableOrigin; origin != nil {
+			// 转换为工作表中的位置
+			origin.HeaderRow += block.top
+			origin.StartCol += block.left
+			origin.Cell, _ = excelize.CoordinatesToCellName(origin.StartCol, origin.HeaderRow)
+			sheetOrigin(origin, positions)
+		}
+		tables = append(tables, result)
+	}
+	return ExcelParseResult{Tables: tables}, nil
+}
+
+// readAllRows 读取所有行，读取器能提供类型化的单元格值时同时读取（否则为nil）
+func readAllRows(reader rowReader) ([][]string, [][]typedCell, error) {
+	typedReader, _ := reader.(typedRowReader)
+
+	var rows [][]string
+	var cells [][]typedCell
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if typedReader != nil {
+			cells = append(cells, typedReader.CellValues(len(rows)))
+		}
+		rows = append(rows, row)
+	}
+	return rows, cells, nil
+}
+
+// segmentTables 将工作表分割为以空行、空列为边界的矩形区域，按从上到下、从左到右的顺序返回
+// 交替按整行为空和整列为空的位置切分（XY切分），直到区域不能再切分
+func segmentTables(rows [][]string) []tableBlock {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	block, ok := trimBlock(rows, tableBlock{top: 0, bottom: len(rows) - 1, left: 0, right: width - 1})
+	if !ok {
+		return nil
+	}
+	return splitBlock(rows, block)
+}
+
+// splitBlock 递归切分区域
+func splitBlock(rows [][]string, block tableBlock) []tableBlock {
+	parts := splitByEmptyRows(rows, block)
+	if len(parts) == 1 {
+		parts = splitByEmptyCols(rows, block)
+	}
+	if len(parts) == 1 {
+		return parts
+	}
+
+	var blocks []tableBlock
+	for _, part := range parts {
+		blocks = append(blocks, splitBlock(rows, part)...)
+	}
+	return blocks
+}
+
+// splitByEmptyRows 按区域内整行为空的位置切分区域
+func splitByEmptyRows(rows [][]string, block tableBlock) []tableBlock {
+	var parts []tableBlock
+	start := -1
+	for row := block.top; row <= block.bottom+1; row++ {
+		empty := row > block.bottom || isEmptyRange(rows[row], block.left, block.right)
+		switch {
+		case !empty && start == -1:
+			start = row
+		case empty && start != -1:
+			part := tableBlock{top: start, bottom: row - 1, left: block.left, right: block.right}
+			if part, ok := trimBlock(rows, part); ok {
+				parts = append(parts, part)
+			}
+			start = -1
+		}
+	}
+	return parts
+}
+
+// splitByEmptyCols 按区域内整列为空的位置切分区域
+func splitByEmptyCols(rows [][]string, block tableBlock) []tableBlock {
+	var parts []tableBlock
+	start := -1
+	for col := block.left; col <= block.right+1; col++ {
+		empty := col > block.right || isEmptyColumn(rows, col, block.top, block.bottom)
+		switch {
+		case !empty && start == -1:
+			start = col
+		case empty && start != -1:
+			part := tableBlock{top: block.top, bottom: block.bottom, left: start, right: col - 1}
+			if part, ok := trimBlock(rows, part); ok {
+				parts = append(parts, part)
+			}
+			start = -1
+		}
+	}
+	return parts
+}
+
+// trimBlock 去掉区域四周的空行和空列，区域内没有内容时返回false
+func trimBlock(rows [][]string, block tableBlock) (tableBlock, bool) {
+	for block.top <= block.bottom && isEmptyRange(rows[block.top], block.left, block.right) {
+		block.top++
+	}
+	for block.bottom >= block.top && isEmptyRange(rows[block.bottom], block.left, block.right) {
+		block.bottom--
+	}
+	if block.top > block.bottom {
+		return block, false
+	}
+	for block.left <= block.right && isEmptyColumn(rows, block.left, block.top, block.bottom) {
+		block.left++
+	}
+	for block.right >= block.left && isEmptyColumn(rows, block.right, block.top, block.bottom) {
+		block.right--
+	}
+	return block, block.left <= block.right
+}
+
+// isEmptyRange 检查行中指定范围内的单元格是否都为空
+func isEmptyRange(row []string, left, right int) bool {
+	for col := left; col <= right && col < len(row); col++ {
+		if strings.TrimSpace(row[col]) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// isEmptyColumn 检查列在指定行范围内是否都为空
+func isEmptyColumn(rows [][]string, col, top, bottom int) bool {
+	for row := top; row <= bottom; row++ {
+		if col < len(rows[row]) && strings.TrimSpace(rows[row][col]) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// sliceColumns 截取行中指定范围的单元格
+func sliceColumns[T any](row []T, left, right int) []T {
+	if left >= len(row) {
+		return nil
+	}
+	return row[left:min(right+1, len(row))]
+}
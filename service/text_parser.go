@@ -14,31 +14,34 @@ import (
 )
 
 // ParseTextFile 解析文本文件
-func ParseTextFile(filePath string) (string, error) {
+// encodingName为空时自动检测字符编码，返回转换为UTF-8的文本内容和使用的编码
+func ParseTextFile(filePath string, encodingName string) (string, string, error) {
 	// 读取文件内容
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	// 返回文本内容
-	return string(data), nil
+	return decodeBytes(data, encodingName)
 }
 
 // ParseComplexFile 解析复杂文件（Word、PDF等）
-func ParseComplexFile(filePath string, fileInfo *model.FileInfo) (string, error) {
+// 返回文本内容和字符编码（只有Go直接处理的文本文件会返回编码）
+func ParseComplexFile(filePath string, fileInfo *model.FileInfo, options model.ParseOptions) (string, string, error) {
 	// 检查文件是否存在
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return "", errors.New("文件不存在")
+		return "", "", errors.New("文件不存在")
 	}
 
 	// 尝试使用Go处理
 	if fileInfo.IsText() {
-		return ParseTextFile(filePath)
+		return ParseTextFile(filePath, options.Encoding)
 	}
 
 	// 对于复杂文件，调用Python辅助服务
-	return callPythonService(filePath, fileInfo)
+	content, err := callPythonService(filePath, fileInfo)
+	return content, "", err
 }
 
 // callPythonService 调用Python辅助服务解析文件
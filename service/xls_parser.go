@@ -0,0 +1,756 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"file-url-parser/model"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"unicode/utf16"
+
+	"github.com/richardlehane/mscfb"
+	"github.com/xuri/excelize/v2"
+)
+
+// BIFF8记录类型
+const (
+	xlsRecordFormula    = 0x0006
+	xlsRecordEOF        = 0x000A
+	xlsRecordDateMode   = 0x0022
+	xlsRecordContinue   = 0x003C
+	xlsRecordColInfo    = 0x007D
+	xlsRecordBoundSheet = 0x0085
+	xlsRecordMulRK      = 0x00BD
+	xlsRecordXF         = 0x00E0
+	xlsRecordMergeCells = 0x00E5
+	xlsRecordSST        = 0x00FC
+	xlsRecordLabelSST   = 0x00FD
+	xlsRecordDimensions = 0x0200
+	xlsRecordNumber     = 0x0203
+	xlsRecordLabel      = 0x0204
+	xlsRecordBoolErr    = 0x0205
+	xlsRecordString     = 0x0207
+	xlsRecordRow        = 0x0208
+	xlsRecordRK         = 0x027E
+	xlsRecordFormat     = 0x041E
+	xlsRecordBOF        = 0x0809
+)
+
+// xlsBIFF8Version BOF记录中BIFF8格式的版本号
+const xlsBIFF8Version = 0x0600
+
+// BIFF8工作表的最大行数和列数
+const (
+	xlsMaxRows    = 65536
+	xlsMaxColumns = 256
+)
+
+// ole2Signature OLE2复合文档的文件头标识
+var ole2Signature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// xlsErrorCodes BOOLERR/FORMULA记录中的错误值
+var xlsErrorCodes = map[byte]string{
+	0x00: "#NULL!",
+	0x07: "#DIV/0!",
+	0x0F: "#VALUE!",
+	0x17: "#REF!",
+	0x1D: "#NAME?",
+	0x24: "#NUM!",
+	0x2A: "#N/A",
+}
+
+// xlsSheet .xls工作簿中的工作表
+type xlsSheet struct {
+	name    string
+	visible bool
+	offset  uint32 // 工作表BOF记录在Workbook流中的位置
+}
+
+// xlsWorkbook BIFF8格式（Excel 97-2003 .xls）工作簿
+// 从OLE2复合文档中读取Workbook流，解析共享字符串、数字格式和各工作表的单元格
+type xlsWorkbook struct {
+	stream   []byte
+	sheets   []xlsSheet
+	sst      []string
+	xfFormat []int          // XF索引 -> 数字格式索引
+	formats  map[int]string // 自定义数字格式
+	date1904 bool
+}
+
+// isXLSFile 检查文件是否为OLE2复合文档（.xls）
+func isXLSFile(filePath string) bool {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header := make([]byte, len(ole2Signature))
+	if _, err := io.ReadFull(file, header); err != nil {
+		return false
+	}
+	return bytes.Equal(header, ole2Signature)
+}
+
+// openXLSWorkbook 打开.xls工作簿并解析全局信息
+func openXLSWorkbook(filePath string) (*xlsWorkbook, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	doc, err := mscfb.New(file)
+	if err != nil {
+		return nil, errors.New("无效的.xls文件: " + err.Error())
+	}
+
+	// 查找Workbook流（BIFF5中名为Book）
+	var stream []byte
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		if entry.Name == "Workbook" || entry.Name == "Book" {
+			if stream, err = io.ReadAll(entry); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if stream == nil {
+		return nil, errors.New("无效的.xls文件: 找不到Workbook数据流（可能是加密的文件）")
+	}
+
+	wb := &xlsWorkbook{
+		stream:  stream,
+		formats: make(map[int]string),
+	}
+	if err := wb.readGlobals(); err != nil {
+		return nil, err
+	}
+	return wb, nil
+}
+
+// Close 释放工作簿占用的资源
+func (wb *xlsWorkbook) Close() error {
+	wb.stream = nil
+	return nil
+}
+
+// GetSheetList 获取工作表名称列表
+func (wb *xlsWorkbook) GetSheetList() []string {
+	names := make([]string, len(wb.sheets))
+	for i, sheet := range wb.sheets {
+		names[i] = sheet.name
+	}
+	return names
+}
+
+// GetSheetVisible 获取工作表是否可见
+func (wb *xlsWorkbook) GetSheetVisible(sheetName string) (bool, error) {
+	sheet, err := wb.sheet(sheetName)
+	if err != nil {
+		return false, err
+	}
+	return sheet.visible, nil
+}
+
+// GetSheetDimension 获取工作表已使用的单元格范围
+func (wb *xlsWorkbook) GetSheetDimension(sheetName string) (string, error) {
+	sheet, err := wb.sheet(sheetName)
+	if err != nil {
+		return "", err
+	}
+
+	var dimension string
+	err = wb.walkSheet(sheet, func(recordType uint16, data []byte) error {
+		if recordType != xlsRecordDimensions || len(data) < 12 {
+			return nil
+		}
+		firstRow := int(binary.LittleEndian.Uint32(data[0:]))
+		lastRow := int(binary.LittleEndian.Uint32(data[4:])) // 最后一行的下一行
+		firstCol := int(binary.LittleEndian.Uint16(data[8:]))
+		lastCol := int(binary.LittleEndian.Uint16(data[10:])) // 最后一列的下一列
+		if lastRow <= firstRow || lastCol <= firstCol {
+			return errStopWalk
+		}
+		start, _ := excelize.CoordinatesToCellName(firstCol+1, firstRow+1)
+		end, _ := excelize.CoordinatesToCellName(lastCol, lastRow)
+		dimension = start + ":" + end
+		return errStopWalk
+	})
+	return dimension, err
+}
+
+// GetMergeCells 获取工作表的合并单元格区域（不包含单元格的值），无效的区域被忽略
+func (wb *xlsWorkbook) GetMergeCells(sheetName string) ([]mergeRange, error) {
+	sheet, err := wb.sheet(sheetName)
+	if err != nil {
+		return nil, err
+	}
+
+	var merges []mergeRange
+	err = wb.walkSheet(sheet, func(recordType uint16, data []byte) error {
+		if recordType != xlsRecordMergeCells || len(data) < 2 {
+			return nil
+		}
+		count := int(binary.LittleEndian.Uint16(data))
+		for i := 0; i < count && 2+i*8+8 <= len(data); i++ {
+			ref := data[2+i*8:]
+			merge := mergeRange{
+				startRow: int(binary.LittleEndian.Uint16(ref[0:])),
+				endRow:   int(binary.LittleEndian.Uint16(ref[2:])),
+				startCol: int(binary.LittleEndian.Uint16(ref[4:])),
+				endCol:   int(binary.LittleEndian.Uint16(ref[6:])),
+			}
+			// 忽略起止行列颠倒和超出256列的区域
+			if merge.endRow < merge.startRow || merge.endCol < merge.startCol || merge.startCol >= xlsMaxColumns {
+				continue
+			}
+			merge.endCol = min(merge.endCol, xlsMaxColumns-1)
+			merges = append(merges, merge)
+		}
+		return nil
+	})
+	return merges, err
+}
+
+// GetHiddenRowsCols 获取工作表中隐藏的行（ROW记录）和隐藏的列（COLINFO记录），行列索引从0开始
+func (wb *xlsWorkbook) GetHiddenRowsCols(sheetName string) (map[int]bool, map[int]bool, error) {
+	sheet, err := wb.sheet(sheetName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hiddenRows := make(map[int]bool)
+	hiddenCols := make(map[int]bool)
+	err = wb.walkSheet(sheet, func(recordType uint16, data []byte) error {
+		switch {
+		case recordType == xlsRecordRow && len(data) >= 16:
+			// 选项标志的fDyZero位表示行高为0（隐藏）
+			if binary.LittleEndian.Uint16(data[12:])&0x0020 != 0 {
+				hiddenRows[int(binary.LittleEndian.Uint16(data[0:]))] = true
+			}
+		case recordType == xlsRecordColInfo && len(data) >= 10:
+			// 选项标志的fHidden位表示列隐藏，一条记录可以设置多列
+			if binary.LittleEndian.Uint16(data[8:])&0x0001 != 0 {
+				first := int(binary.LittleEndian.Uint16(data[0:]))
+				last := int(binary.LittleEndian.Uint16(data[2:]))
+				for col := first; col <= last && col < xlsMaxColumns; col++ {
+					hiddenCols[col] = true
+				}
+			}
+		}
+		return nil
+	})
+	return hiddenRows, hiddenCols, err
+}
+
+// GetRows 读取工作表的所有行，返回与excelize.GetRows相同格式的二维数组，以及对应的类型化单元格值
+// 每行末尾的空单元格和工作表末尾的空行会被去掉；行列索引来自文件内容，超出DIMENSIONS记录的行数或256列的单元格会被忽略
+func (wb *xlsWorkbook) GetRows(sheetName string) ([][]string, [][]typedCell, error) {
+	sheet, err := wb.sheet(sheetName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows [][]string
+	var cells [][]typedCell
+	maxRows := xlsMaxRows
+	setCell := func(row, col int, value string, cell typedCell) {
+		if value == "" || row >= maxRows || col >= xlsMaxColumns {
+			return
+		}
+		for len(rows) <= row {
+			rows = append(rows, nil)
+			cells = append(cells, nil)
+		}
+		for len(rows[row]) <= col {
+			rows[row] = append(rows[row], "")
+			cells[row] = append(cells[row], typedCell{})
+		}
+		rows[row][col] = value
+		cells[row][col] = cell
+	}
+	setText := func(row, col int, value string) {
+		setCell(row, col, value, typedCell{raw: value})
+	}
+	setNumber := func(row, col int, value float64, xf int) {
+		text, cell := wb.numberCell(value, xf)
+		setCell(row, col, text, cell)
+	}
+	setBoolErr := func(row, col int, value byte, isError bool) {
+		text := formatBoolErr(value, isError)
+		if isError {
+			setText(row, col, text)
+			return
+		}
+		setCell(row, col, text, typedCell{raw: strconv.Itoa(int(value)), value: value != 0})
+	}
+
+	// FORMULA记录的字符串结果保存在紧随其后的STRING记录中
+	pendingRow, pendingCol := -1, -1
+	err = wb.walkSheet(sheet, func(recordType uint16, data []byte) error {
+		switch recordType {
+		case xlsRecordDimensions:
+			// 最后一行的下一行，记录无效（如空工作表）时不限制
+			if len(data) < 12 {
+				return nil
+			}
+			firstRow := int(binary.LittleEndian.Uint32(data[0:]))
+			lastRow := int(binary.LittleEndian.Uint32(data[4:]))
+			if lastRow > firstRow && lastRow < maxRows {
+				maxRows = lastRow
+			}
+		case xlsRecordLabelSST:
+			if len(data) < 10 {
+				return nil
+			}
+			row, col := int(binary.LittleEndian.Uint16(data[0:])), int(binary.LittleEndian.Uint16(data[2:]))
+			index := int(binary.LittleEndian.Uint32(data[6:]))
+			if index < len(wb.sst) {
+				setText(row, col, wb.sst[index])
+			}
+		case xlsRecordLabel:
+			if len(data) < 6 {
+				return nil
+			}
+			row, col := int(binary.LittleEndian.Uint16(data[0:])), int(binary.LittleEndian.Uint16(data[2:]))
+			value, _ := readXLUnicodeString(data[6:])
+			setText(row, col, value)
+		case xlsRecordNumber:
+			if len(data) < 14 {
+				return nil
+			}
+			row, col := int(binary.LittleEndian.Uint16(data[0:])), int(binary.LittleEndian.Uint16(data[2:]))
+			xf := int(binary.LittleEndian.Uint16(data[4:]))
+			value := math.Float64frombits(binary.LittleEndian.Uint64(data[6:]))
+			setNumber(row, col, value, xf)
+		case xlsRecordRK:
+			if len(data) < 10 {
+				return nil
+			}
+			row, col := int(binary.LittleEndian.Uint16(data[0:])), int(binary.LittleEndian.Uint16(data[2:]))
+			xf := int(binary.LittleEndian.Uint16(data[4:]))
+			value := decodeRK(binary.LittleEndian.Uint32(data[6:]))
+			setNumber(row, col, value, xf)
+		case xlsRecordMulRK:
+			if len(data) < 6 {
+				return nil
+			}
+			row, firstCol := int(binary.LittleEndian.Uint16(data[0:])), int(binary.LittleEndian.Uint16(data[2:]))
+			// 每个单元格占6字节（XF索引2字节+RK值4字节），最后2字节为末列索引
+			for i, offset := 0, 4; offset+6 <= len(data)-2 && firstCol+i < xlsMaxColumns; i, offset = i+1, offset+6 {
+				xf := int(binary.LittleEndian.Uint16(data[offset:]))
+				value := decodeRK(binary.LittleEndian.Uint32(data[offset+2:]))
+				setNumber(row, firstCol+i, value, xf)
+			}
+		case xlsRecordBoolErr:
+			if len(data) < 8 {
+				return nil
+			}
+			row, col := int(binary.LittleEndian.Uint16(data[0:])), int(binary.LittleEndian.Uint16(data[2:]))
+			setBoolErr(row, col, data[6], data[7] != 0)
+		case xlsRecordFormula:
+			if len(data) < 14 {
+				return nil
+			}
+			row, col := int(binary.LittleEndian.Uint16(data[0:])), int(binary.LittleEndian.Uint16(data[2:]))
+			xf := int(binary.LittleEndian.Uint16(data[4:]))
+			result := data[6:14]
+			if result[6] != 0xFF || result[7] != 0xFF {
+				// 数值结果
+				value := math.Float64frombits(binary.LittleEndian.Uint64(result))
+				setNumber(row, col, value, xf)
+				return nil
+			}
+			switch result[0] {
+			case 0: // 字符串结果，值在后续的STRING记录中
+				pendingRow, pendingCol = row, col
+			case 1: // 布尔值
+				setBoolErr(row, col, result[2], false)
+			case 2: // 错误值
+				setBoolErr(row, col, result[2], true)
+			}
+		case xlsRecordString:
+			if pendingRow >= 0 {
+				value, _ := readXLUnicodeString(data)
+				setText(pendingRow, pendingCol, value)
+				pendingRow, pendingCol = -1, -1
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rows, cells, nil
+}
+
+// sheet 根据名称查找工作表
+func (wb *xlsWorkbook) sheet(sheetName string) (xlsSheet, error) {
+	for _, sheet := range wb.sheets {
+		if sheet.name == sheetName {
+			return sheet, nil
+		}
+	}
+	return xlsSheet{}, errors.New("工作表不存在: " + sheetName)
+}
+
+// errStopWalk 用于提前结束记录遍历
+var errStopWalk = errors.New("stop walk")
+
+// walkRecords 从指定位置开始遍历BIFF记录，直到子流的EOF记录
+// CONTINUE记录作为单独的记录传给回调函数；嵌入的子流（如工作表中图表的BOF到EOF）有自己的EOF记录，其中的记录不传给回调函数
+func (wb *xlsWorkbook) walkRecords(offset int, handle func(recordType uint16, data []byte) error) error {
+	depth := 0
+	for offset+4 <= len(wb.stream) {
+		recordType := binary.LittleEndian.Uint16(wb.stream[offset:])
+		size := int(binary.LittleEndian.Uint16(wb.stream[offset+2:]))
+		offset += 4
+		if offset+size > len(wb.stream) {
+			return errors.New("无效的.xls文件: 记录长度超出数据流范围")
+		}
+		data := wb.stream[offset : offset+size]
+		offset += size
+
+		if recordType == xlsRecordBOF {
+			depth++
+		}
+		if depth <= 1 {
+			if err := handle(recordType, data); err != nil {
+				if err == errStopWalk {
+					return nil
+				}
+				return err
+			}
+		}
+		if recordType == xlsRecordEOF {
+			depth--
+			if depth <= 0 {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// walkSheet 遍历工作表子流中的记录
+func (wb *xlsWorkbook) walkSheet(sheet xlsSheet, handle func(recordType uint16, data []byte) error) error {
+	if int(sheet.offset) >= len(wb.stream) {
+		return errors.New("无效的.xls文件: 工作表位置超出数据流范围")
+	}
+	return wb.walkRecords(int(sheet.offset), handle)
+}
+
+// readGlobals 解析工作簿全局子流：工作表列表、共享字符串、数字格式和日期模式
+func (wb *xlsWorkbook) readGlobals() error {
+	var sstSegments [][]byte
+	inSST := false
+	first := true
+
+	err := wb.walkRecords(0, func(recordType uint16, data []byte) error {
+		if first {
+			first = false
+			if recordType != xlsRecordBOF || len(data) < 2 {
+				return errors.New("无效的.xls文件: 缺少BOF记录")
+			}
+			if binary.LittleEndian.Uint16(data) != xlsBIFF8Version {
+				return errors.New("只支持Excel 97-2003（BIFF8）格式的.xls文件")
+			}
+			return nil
+		}
+
+		// SST记录可能被拆分到多个CONTINUE记录中
+		if recordType == xlsRecordContinue && inSST {
+			sstSegments = append(sstSegments, data)
+			return nil
+		}
+		inSST = false
+
+		switch recordType {
+		case xlsRecordBoundSheet:
+			if len(data) < 8 {
+				return nil
+			}
+			// 只处理普通工作表，忽略图表、宏表等
+			if data[5] != 0 {
+				return nil
+			}
+			name, _ := readShortXLUnicodeString(data[6:])
+			wb.sheets = append(wb.sheets, xlsSheet{
+				name:    name,
+				visible: data[4]&0x03 == 0,
+				offset:  binary.LittleEndian.Uint32(data[0:]),
+			})
+		case xlsRecordSST:
+			sstSegments = [][]byte{data}
+			inSST = true
+		case xlsRecordFormat:
+			if len(data) < 2 {
+				return nil
+			}
+			code, _ := readXLUnicodeString(data[2:])
+			wb.formats[int(binary.LittleEndian.Uint16(data))] = code
+		case xlsRecordXF:
+			if len(data) < 4 {
+				return nil
+			}
+			wb.xfFormat = append(wb.xfFormat, int(binary.LittleEndian.Uint16(data[2:])))
+		case xlsRecordDateMode:
+			if len(data) >= 2 {
+				wb.date1904 = binary.LittleEndian.Uint16(data) == 1
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(sstSegments) > 0 {
+		wb.sst = readSST(sstSegments)
+	}
+	return nil
+}
+
+// numberCell 按单元格的数字格式转换数值，返回显示文本和类型化的值
+// 日期时间格式转换为ISO格式，百分比格式的显示文本保留百分号，其他格式按常规格式输出
+func (wb *xlsWorkbook) numberCell(value float64, xf int) (string, typedCell) {
+	formatIndex := 0
+	if xf >= 0 && xf < len(wb.xfFormat) {
+		formatIndex = wb.xfFormat[xf]
+	}
+	formatCode, ok := wb.formats[formatIndex]
+	if !ok {
+		formatCode = builtInNumberFormats[formatIndex]
+	}
+
+	raw := formatGeneralNumber(value)
+	cell := typedCell{raw: raw, value: excelNumber(raw)}
+	switch kind := classifyNumberFormat(formatCode); kind {
+	case numberFormatDate, numberFormatTime, numberFormatDateTime:
+		if date, ok := serialDate(value, kind, wb.date1904); ok {
+			return date.format(model.DateOptions{}), typedCell{raw: raw, value: date}
+		}
+	case numberFormatPercent:
+		return strconv.FormatFloat(value*100, 'f', percentDecimals(formatCode), 64) + "%", cell
+	}
+	return raw, cell
+}
+
+// formatBoolErr 转换布尔值或错误值
+func formatBoolErr(value byte, isError bool) string {
+	if isError {
+		if code, ok := xlsErrorCodes[value]; ok {
+			return code
+		}
+		return "#ERR!"
+	}
+	if value != 0 {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// decodeRK 解码RK格式的数值
+func decodeRK(rk uint32) float64 {
+	var value float64
+	if rk&0x02 != 0 {
+		// 30位有符号整数
+		value = float64(int32(rk) >> 2)
+	} else {
+		// IEEE 754浮点数的高30位
+		value = math.Float64frombits(uint64(rk&0xFFFFFFFC) << 32)
+	}
+	if rk&0x01 != 0 {
+		value /= 100
+	}
+	return value
+}
+
+// readShortXLUnicodeString 读取1字节长度前缀的字符串
+func readShortXLUnicodeString(data []byte) (string, int) {
+	if len(data) < 2 {
+		return "", len(data)
+	}
+	return readXLChars(data[2:], int(data[0]), data[1]&0x01 != 0, 2)
+}
+
+// readXLUnicodeString 读取2字节长度前缀的字符串
+func readXLUnicodeString(data []byte) (string, int) {
+	if len(data) < 3 {
+		return "", len(data)
+	}
+	return readXLChars(data[3:], int(binary.LittleEndian.Uint16(data)), data[2]&0x01 != 0, 3)
+}
+
+// readXLChars 读取指定数量的字符，highByte为true时每个字符占2字节（UTF-16LE），否则占1字节
+// 返回字符串和包含头部在内消耗的字节数
+func readXLChars(data []byte, count int, highByte bool, headerSize int) (string, int) {
+	if highByte {
+		if count*2 > len(data) {
+			count = len(data) / 2
+		}
+		units := make([]uint16, count)
+		for i := range units {
+			units[i] = binary.LittleEndian.Uint16(data[i*2:])
+		}
+		return string(utf16.Decode(units)), headerSize + count*2
+	}
+
+	if count > len(data) {
+		count = len(data)
+	}
+	return latin1ToString(data[:count]), headerSize + count
+}
+
+// latin1ToString 将压缩存储的单字节字符（UTF-16高字节为0）转换为字符串
+func latin1ToString(data []byte) string {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// sstReader 跨越SST和CONTINUE记录读取数据
+type sstReader struct {
+	segments [][]byte
+	segment  int
+	offset   int
+}
+
+// readBytes 读取指定字节数，可跨越记录边界
+func (r *sstReader) readBytes(n int) ([]byte, error) {
+	// 长度来自文件内容，按剩余的字节数分配，避免无效的长度导致分配过多内存
+	result := make([]byte, 0, min(n, r.remaining()))
+	for len(result) < n {
+		if r.segment >= len(r.segments) {
+			return result, io.ErrUnexpectedEOF
+		}
+		current := r.segments[r.segment]
+		if r.offset >= len(current) {
+			r.segment++
+			r.offset = 0
+			continue
+		}
+		take := n - len(result)
+		if remaining := len(current) - r.offset; take > remaining {
+			take = remaining
+		}
+		result = append(result, current[r.offset:r.offset+take]...)
+		r.offset += take
+	}
+	return result, nil
+}
+
+// remaining 返回尚未读取的字节数
+func (r *sstReader) remaining() int {
+	total := 0
+	for i := r.segment; i < len(r.segments); i++ {
+		total += len(r.segments[i])
+	}
+	if r.segment < len(r.segments) {
+		total -= min(r.offset, len(r.segments[r.segment]))
+	}
+	return total
+}
+
+// readChars 读取字符数据，字符跨越CONTINUE记录时新记录开头会重新给出字符宽度标志
+func (r *sstReader) readChars(count int, highByte bool) (string, error) {
+	var units []uint16
+	for count > 0 {
+		if r.segment >= len(r.segments) {
+			return "", io.ErrUnexpectedEOF
+		}
+		current := r.segments[r.segment]
+		if r.offset >= len(current) {
+			r.segment++
+			r.offset = 0
+			if r.segment >= len(r.segments) || len(r.segments[r.segment]) == 0 {
+				return "", io.ErrUnexpectedEOF
+			}
+			highByte = r.segments[r.segment][0]&0x01 != 0
+			r.offset = 1
+			continue
+		}
+
+		width := 1
+		if highByte {
+			width = 2
+		}
+		available := (len(current) - r.offset) / width
+		if available == 0 {
+			// 剩余字节不足一个字符，视为记录结束
+			r.offset = len(current)
+			continue
+		}
+		take := count
+		if take > available {
+			take = available
+		}
+		for i := 0; i < take; i++ {
+			if highByte {
+				units = append(units, binary.LittleEndian.Uint16(current[r.offset:]))
+			} else {
+				units = append(units, uint16(current[r.offset]))
+			}
+			r.offset += width
+		}
+		count -= take
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+// readSST 解析共享字符串表
+func readSST(segments [][]byte) []string {
+	reader := &sstReader{segments: segments}
+	header, err := reader.readBytes(8)
+	if err != nil {
+		return nil
+	}
+	unique := int(binary.LittleEndian.Uint32(header[4:]))
+
+	// 每个字符串至少占3个字节（字符数和标志），字符串数量不会超过剩余字节数的三分之一
+	strs := make([]string, 0, min(unique, reader.remaining()/3))
+	for i := 0; i < unique; i++ {
+		head, err := reader.readBytes(3)
+		if err != nil {
+			break
+		}
+		count := int(binary.LittleEndian.Uint16(head))
+		flags := head[2]
+
+		// 富文本和扩展信息的长度
+		runs, extSize := 0, 0
+		if flags&0x08 != 0 {
+			b, err := reader.readBytes(2)
+			if err != nil {
+				break
+			}
+			runs = int(binary.LittleEndian.Uint16(b))
+		}
+		if flags&0x04 != 0 {
+			b, err := reader.readBytes(4)
+			if err != nil {
+				break
+			}
+			extSize = int(binary.LittleEndian.Uint32(b))
+		}
+
+		value, err := reader.readChars(count, flags&0x01 != 0)
+		if err != nil {
+			break
+		}
+		strs = append(strs, value)
+
+		// 跳过富文本格式和扩展信息
+		if _, err := reader.readBytes(runs*4 + extSize); err != nil {
+			break
+		}
+	}
+	return strs
+}
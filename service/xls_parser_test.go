@@ -0,0 +1,218 @@
+package service
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// xlsRecord 生成一条BIFF记录（类型、长度和数据）
+func xlsRecord(recordType uint16, data []byte) []byte {
+	record := binary.LittleEndian.AppendUint16(nil, recordType)
+	record = binary.LittleEndian.AppendUint16(record, uint16(len(data)))
+	return append(record, data...)
+}
+
+// xlsUint16s 按小端序拼接16位整数
+func xlsUint16s(values ...int) []byte {
+	var data []byte
+	for _, value := range values {
+		data = binary.LittleEndian.AppendUint16(data, uint16(value))
+	}
+	return data
+}
+
+// xlsCell 生成单元格记录的数据：行、列、XF索引和值
+func xlsCell(row, col, xf int, value []byte) []byte {
+	return append(xlsUint16s(row, col, xf), value...)
+}
+
+// xlsDimensions 生成DIMENSIONS记录，lastRow和lastCol为最后一行和最后一列的下一行、下一列
+func xlsDimensions(firstRow, lastRow, firstCol, lastCol int) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, uint32(firstRow))
+	data = binary.LittleEndian.AppendUint32(data, uint32(lastRow))
+	return xlsRecord(xlsRecordDimensions, append(data, xlsUint16s(firstCol, lastCol, 0)...))
+}
+
+// xlsRK 将整数编码为RK值，cents为true时值为整数的百分之一
+func xlsRK(value int32, cents bool) uint32 {
+	rk := uint32(value)<<2 | 0x02
+	if cents {
+		rk |= 0x01
+	}
+	return rk
+}
+
+// xlsTestWorkbook 生成包含一个工作表的Workbook流并解析全局信息
+// sst为SST记录及其CONTINUE记录，sheet为工作表BOF和EOF之间的记录
+func xlsTestWorkbook(t *testing.T, sst [][]byte, sheet ...[]byte) *xlsWorkbook {
+	t.Helper()
+	var globals []byte
+	globals = append(globals, xlsRecord(xlsRecordBOF, xlsUint16s(xlsBIFF8Version, 0x0005, 0, 0, 0, 0, 0, 0))...)
+	// XF 0为常规格式，XF 1为日期格式（内置格式14），XF 2为日期时间格式（自定义格式164）
+	for _, format := range []int{0, 14, 164} {
+		globals = append(globals, xlsRecord(xlsRecordXF, append(xlsUint16s(0, format), make([]byte, 16)...))...)
+	}
+	code := "yyyy/m/d h:mm"
+	globals = append(globals, xlsRecord(xlsRecordFormat, append(xlsUint16s(164, len(code)), append([]byte{0}, code...)...))...)
+	for _, record := range sst {
+		globals = append(globals, record...)
+	}
+
+	name := "Sheet1"
+	boundSheet := append([]byte{0, 0, 0, 0, 0, 0, byte(len(name)), 0}, name...)
+	boundSheetAt := len(globals) + 4
+	globals = append(globals, xlsRecord(xlsRecordBoundSheet, boundSheet)...)
+	globals = append(globals, xlsRecord(xlsRecordEOF, nil)...)
+	binary.LittleEndian.PutUint32(globals[boundSheetAt:], uint32(len(globals)))
+
+	stream := globals
+	stream = append(stream, xlsRecord(xlsRecordBOF, xlsUint16s(xlsBIFF8Version, 0x0010, 0, 0, 0, 0, 0, 0))...)
+	for _, record := range sheet {
+		stream = append(stream, record...)
+	}
+	stream = append(stream, xlsRecord(xlsRecordEOF, nil)...)
+
+	wb := &xlsWorkbook{stream: stream, formats: make(map[int]string)}
+	if err := wb.readGlobals(); err != nil {
+		t.Fatal(err)
+	}
+	return wb
+}
+
+// TestXLSSharedStringsContinue 共享字符串跨越CONTINUE记录时，新记录开头的标志可以改变字符宽度
+func TestXLSSharedStringsContinue(t *testing.T) {
+	sst := binary.LittleEndian.AppendUint32(nil, 3)
+	sst = binary.LittleEndian.AppendUint32(sst, 3)
+	sst = append(sst, xlsUint16s(2)...)
+	sst = append(sst, 0, 'i', 'd')
+	// 第二个字符串前3个字符在SST记录中按单字节存储，其余字符在CONTINUE记录中按UTF-16存储
+	sst = append(sst, xlsUint16s(6)...)
+	sst = append(sst, 0, 'H', 'e', 'l')
+	continued := []byte{0x01}
+	for _, unit := range utf16.Encode([]rune("lo世")) {
+		continued = binary.LittleEndian.AppendUint16(continued, unit)
+	}
+	// 第三个字符串的头部跨越两个CONTINUE记录，从新记录开始的头部不带标志
+	continued = append(continued, xlsUint16s(2)[:1]...)
+	last := append(xlsUint16s(2)[1:], 0, 'o', 'k')
+
+	wb := xlsTestWorkbook(t, [][]byte{
+		xlsRecord(xlsRecordSST, sst),
+		xlsRecord(xlsRecordContinue, continued),
+		xlsRecord(xlsRecordContinue, last),
+	})
+	if want := []string{"id", "Hello世", "ok"}; !reflect.DeepEqual(wb.sst, want) {
+		t.Errorf("共享字符串为 %q，应为 %q", wb.sst, want)
+	}
+}
+
+// TestXLSNumberCells RK、MulRK和NUMBER记录的数值，以及日期格式的单元格
+func TestXLSNumberCells(t *testing.T) {
+	mulRK := xlsUint16s(1, 0)
+	for _, cell := range []struct {
+		xf int
+		rk uint32
+	}{
+		{0, xlsRK(-7, false)},
+		{0, xlsRK(1234, true)},
+		{0, uint32(math.Float64bits(1.5) >> 32)},
+		{1, xlsRK(45292, false)},
+	} {
+		mulRK = append(mulRK, xlsUint16s(cell.xf)...)
+		mulRK = binary.LittleEndian.AppendUint32(mulRK, cell.rk)
+	}
+	mulRK = append(mulRK, xlsUint16s(3)...)
+
+	number := binary.LittleEndian.AppendUint64(nil, math.Float64bits(45292.5))
+	wb := xlsTestWorkbook(t, nil,
+		xlsRecord(xlsRecordRK, xlsCell(0, 0, 0, binary.LittleEndian.AppendUint32(nil, xlsRK(42, false)))),
+		xlsRecord(xlsRecordMulRK, mulRK),
+		xlsRecord(xlsRecordNumber, xlsCell(2, 0, 2, number)),
+		xlsRecord(xlsRecordNumber, xlsCell(2, 1, 0, number)),
+	)
+
+	rows, cells, err := wb.GetRows("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"42"},
+		{"-7", "12.34", "1.5", "2024-01-01"},
+		{"2024-01-01 12:00:00", "45292.5"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("读取的行为 %q，应为 %q", rows, want)
+	}
+	if value, ok := cells[1][3].value.(dateValue); !ok || !value.time.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("日期格式单元格的值为 %#v，应为2024-01-01的日期", cells[1][3].value)
+	}
+	if cells[2][1].value != excelNumber("45292.5") {
+		t.Errorf("常规格式单元格的值为 %#v，应为数值 45292.5", cells[2][1].value)
+	}
+}
+
+// TestXLSRowsBounds 超出DIMENSIONS记录的行和超过256列的单元格被忽略，嵌入子流中的记录不作为工作表的单元格
+func TestXLSRowsBounds(t *testing.T) {
+	rk := binary.LittleEndian.AppendUint32(nil, xlsRK(1, false))
+	wideMulRK := xlsUint16s(1, 254)
+	for i := 0; i < 4; i++ {
+		wideMulRK = append(wideMulRK, xlsUint16s(0)...)
+		wideMulRK = binary.LittleEndian.AppendUint32(wideMulRK, xlsRK(int32(i), false))
+	}
+	wideMulRK = append(wideMulRK, xlsUint16s(257)...)
+
+	wb := xlsTestWorkbook(t, nil,
+		xlsDimensions(0, 2, 0, 256),
+		xlsRecord(xlsRecordRK, xlsCell(0, 0, 0, rk)),
+		xlsRecord(xlsRecordRK, xlsCell(0, 300, 0, rk)),
+		xlsRecord(xlsRecordMulRK, wideMulRK),
+		xlsRecord(xlsRecordRK, xlsCell(60000, 0, 0, rk)),
+		// 图表等嵌入的子流有自己的BOF和EOF
+		xlsRecord(xlsRecordBOF, xlsUint16s(xlsBIFF8Version, 0x0020, 0, 0, 0, 0, 0, 0)),
+		xlsRecord(xlsRecordRK, xlsCell(0, 1, 0, rk)),
+		xlsRecord(xlsRecordEOF, nil),
+		xlsRecord(xlsRecordRK, xlsCell(0, 2, 0, rk)),
+	)
+
+	rows, _, err := wb.GetRows("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("读取了 %d 行，应为 2 行", len(rows))
+	}
+	if want := []string{"1", "", "1"}; !reflect.DeepEqual(rows[0], want) {
+		t.Errorf("第1行为 %q，应为 %q", rows[0], want)
+	}
+	if len(rows[1]) != xlsMaxColumns || rows[1][254] != "0" || rows[1][255] != "1" {
+		t.Errorf("第2行有 %d 列，应只保留前 %d 列", len(rows[1]), xlsMaxColumns)
+	}
+}
+
+// TestXLSTruncatedRecords 记录长度超出数据流或共享字符串表不完整时不会越界
+func TestXLSTruncatedRecords(t *testing.T) {
+	wb := xlsTestWorkbook(t, nil, xlsRecord(xlsRecordRK, xlsCell(0, 0, 0, nil)))
+	// 最后一条记录的长度超出数据流
+	wb.stream = append(wb.stream[:len(wb.stream)-4], xlsUint16s(xlsRecordLabel, 100)...)
+	if _, _, err := wb.GetRows("Sheet1"); err == nil {
+		t.Error("记录长度超出数据流时应返回错误")
+	}
+
+	// 字符串数量和字符数都超过实际的数据
+	sst := binary.LittleEndian.AppendUint32(nil, 1000000)
+	sst = binary.LittleEndian.AppendUint32(sst, 1000000)
+	sst = append(sst, xlsUint16s(2)...)
+	sst = append(sst, 0, 'o', 'k')
+	sst = append(sst, xlsUint16s(60000)...)
+	sst = append(sst, 0x01, 'x')
+	if strs := readSST([][]byte{sst}); !reflect.DeepEqual(strs, []string{"ok"}) {
+		t.Errorf("共享字符串为 %q，应为 [\"ok\"]", strs)
+	}
+	if strs := readSST([][]byte{sst[:5]}); strs != nil {
+		t.Errorf("缺少头部的共享字符串表应返回nil，实际为 %q", strs)
+	}
+}
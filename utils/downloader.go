@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"file-url-parser/model"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// FileTooLargeCode 文件超过最大文件大小时的错误代码
+const FileTooLargeCode = "FILE_TOO_LARGE"
+
+// FileTooLargeError 文件超过最大文件大小的错误
+type FileTooLargeError struct {
+	Limit   int64 // 最大文件大小（字节）
+	Size    int64 // 文件大小（字节），AtLeast为true时为已读取的字节数
+	AtLeast bool  // 响应没有Content-Length，读取到超过限制时停止，实际大小未知
+}
+
+// Error 返回包含文件大小和限制的错误说明
+func (e *FileTooLargeError) Error() string {
+	size := strconv.FormatInt(e.Size, 10) + "字节"
+	if e.AtLeast {
+		size = "超过" + strconv.FormatInt(e.Limit, 10) + "字节"
+	}
+	return "文件太大，超过最大限制: 文件大小" + size + "，最大" + strconv.FormatInt(e.Limit, 10) + "字节"
+}
+
+// Downloader 下载文件的客户端
+// 出站策略和下载设置不变，创建一次后在各个请求间共享，复用同一个连接池，空闲连接按IdleConnTimeout关闭
+type Downloader struct {
+	client   *http.Client
+	policy   model.OutboundPolicy
+	download model.DownloadConfig
+}
+
+// NewDownloader 按出站策略和下载设置创建下载客户端
+func NewDownloader(policy model.OutboundPolicy, download model.DownloadConfig) *Downloader {
+	return &Downloader{
+		client:   newDownloadClient(policy, download),
+		policy:   policy,
+		download: download,
+	}
+}
+
+// retryableError 可以重试的下载错误（连接失败、超时、502等临时错误）
+type retryableError struct {
+	err        error
+	retryAfter time.Duration // 响应头Retry-After要求的等待时间，0表示没有要求
+}
+
+// Error 返回原始错误的说明
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap 返回原始错误
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// newDownloadClient 创建下载文件的HTTP客户端
+// 按出站策略检查每次重定向的目标和每个连接地址，并设置连接、响应头和总超时
+func newDownloadClient(policy model.OutboundPolicy, download model.DownloadConfig) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   download.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+		Control:   policyDialControl(policy),
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// 不使用环境变量中的代理：通过代理时域名由代理解析，无法检查实际访问的地址
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	transport.ResponseHeaderTimeout = download.HeaderTimeout
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   download.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > download.MaxRedirects {
+				return &URLPolicyError{Code: URLCodeTooManyRedirects, Message: "重定向次数过多"}
+			}
+			return checkURL(req.URL, policy)
+		},
+	}
+}
+
+// downloadWithRetry 下载文件，临时错误时按指数退避重试，返回文件内容和响应头
+// ctx取消（如客户端断开连接）时立即停止下载和等待
+func downloadWithRetry(ctx context.Context, client *http.Client, rawURL string, maxSize int64, download model.DownloadConfig) ([]byte, http.Header, error) {
+	for attempt := 0; ; attempt++ {
+		data, header, err := downloadOnce(ctx, client, rawURL, maxSize)
+		if err == nil {
+			return data, header, nil
+		}
+
+		var retryErr *retryableError
+		if !errors.As(err, &retryErr) {
+			return nil, nil, err
+		}
+		if attempt >= download.MaxRetries || ctx.Err() != nil {
+			return nil, nil, retryErr.err
+		}
+		delay, ok := retryDelay(attempt, retryErr.retryAfter, download)
+		if !ok {
+			return nil, nil, retryErr.err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// downloadOnce 发送一次下载请求并读取响应体，可以重试的错误包装为retryableError
+func downloadOnce(ctx context.Context, client *http.Client, rawURL string, maxSize int64) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// 重定向目标或连接地址被出站策略拒绝时只返回策略错误
+		var policyErr *URLPolicyError
+		if errors.As(err, &policyErr) {
+			return nil, nil, policyErr
+		}
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		return nil, nil, &retryableError{err: err}
+	}
+	defer resp.Body.Close()
+
+	// 检查响应状态
+	if resp.StatusCode != http.StatusOK {
+		err := errors.New("下载失败，状态码: " + resp.Status)
+		if isRetryableStatus(resp.StatusCode) {
+			return nil, nil, &retryableError{err: err, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+		}
+		return nil, nil, err
+	}
+
+	// 检查文件大小
+	if resp.ContentLength > maxSize {
+		return nil, nil, &FileTooLargeError{Limit: maxSize, Size: resp.ContentLength}
+	}
+
+	// 读取文件内容，多读取一个字节以判断没有Content-Length（分块传输）的文件是否超过限制，不截断文件
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		return nil, nil, &retryableError{err: err}
+	}
+	if int64(len(data)) > maxSize {
+		return nil, nil, &FileTooLargeError{Limit: maxSize, Size: int64(len(data)), AtLeast: true}
+	}
+	return data, resp.Header, nil
+}
+
+// isRetryableStatus 判断响应状态码是否为可以重试的临时错误
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter 解析Retry-After响应头（秒数或HTTP日期），无法解析时返回0
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
+
+// retryDelay 计算第attempt次（从0开始）重试前的等待时间
+// 按RetryDelay指数增长，不超过RetryMaxDelay；Retry-After要求的时间更长时按其等待，超过RetryMaxDelay时不再重试
+func retryDelay(attempt int, retryAfter time.Duration, download model.DownloadConfig) (time.Duration, bool) {
+	if retryAfter > download.RetryMaxDelay {
+		return 0, false
+	}
+	delay := download.RetryDelay
+	for i := 0; i < attempt && delay < download.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, download.RetryMaxDelay)
+	return max(delay, retryAfter), true
+}
@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// defaultFileName 无法从响应头和URL中得到文件名时使用的文件名
+const defaultFileName = "downloaded_file"
+
+// maxFileNameBytes 文件名的最大长度（字节）
+const maxFileNameBytes = 255
+
+// fileNameWordDecoder 解码RFC 2047编码的文件名（如 =?UTF-8?B?...?=），支持GBK等非UTF-8字符集
+var fileNameWordDecoder = &mime.WordDecoder{
+	CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+		enc, err := htmlindex.Get(charset)
+		if err != nil {
+			return nil, err
+		}
+		return enc.NewDecoder().Reader(input), nil
+	},
+}
+
+// extractFileName 从Content-Disposition或URL中提取文件名
+// Content-Disposition按RFC 6266解析，优先使用filename*；URL路径中的文件名按百分号编码解码；结果去掉目录部分和文件名中不允许的字符
+func extractFileName(rawURL, contentDisposition string) string {
+	if fileName := sanitizeFileName(contentDispositionFileName(contentDisposition)); fileName != "" {
+		return fileName
+	}
+	if fileName := sanitizeFileName(urlFileName(rawURL)); fileName != "" {
+		return fileName
+	}
+	return defaultFileName
+}
+
+// contentDispositionFileName 返回Content-Disposition中的文件名，没有时返回空字符串
+func contentDispositionFileName(contentDisposition string) string {
+	if contentDisposition == "" {
+		return ""
+	}
+	fileName, extFileName := lenientDispositionFileName(contentDisposition)
+	// mime.ParseMediaType会解码filename*（RFC 2231/5987，包括分段的参数）并优先于filename，
+	// 但会忽略UTF-8以外字符集（如GBK）的filename*，此时使用宽松解析得到的filename*
+	if _, params, err := mime.ParseMediaType(contentDisposition); err == nil && params["filename"] != "" {
+		if extFileName != "" {
+			return extFileName
+		}
+		return decodeFileName(params["filename"])
+	}
+	// 不规范的响应头（缺少类型、未加引号的中文或带空格的文件名）使用宽松解析的结果
+	if extFileName != "" {
+		return extFileName
+	}
+	return fileName
+}
+
+// lenientDispositionFileName 宽松地解析Content-Disposition的参数，返回filename和filename*（已解码）
+func lenientDispositionFileName(contentDisposition string) (fileName, extFileName string) {
+	for _, param := range splitDispositionParams(contentDisposition) {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "filename*":
+			extFileName = decodeExtValue(unquoteParam(value))
+		case "filename":
+			fileName = decodeFileName(unquoteParam(value))
+		}
+	}
+	return fileName, extFileName
+}
+
+// splitDispositionParams 按分号拆分响应头的各个部分，忽略引号内的分号
+func splitDispositionParams(value string) []string {
+	var params []string
+	inQuotes, escaped, start := false, false, 0
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case escaped:
+			escaped = false
+		case c == '\\' && inQuotes:
+			escaped = true
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == ';' && !inQuotes:
+			params = append(params, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(params, value[start:])
+}
+
+// unquoteParam 去掉参数值两端的空白和引号，并处理引号内的转义字符
+func unquoteParam(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return value
+	}
+	var sb strings.Builder
+	escaped := false
+	for _, r := range value[1 : len(value)-1] {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// decodeExtValue 解码RFC 5987格式的参数值：字符集'语言'百分号编码的值，如 UTF-8'zh-CN'%E6%8A%A5%E8%A1%A8.xlsx（语言可以为空）
+func decodeExtValue(value string) string {
+	parts := strings.SplitN(value, "'", 3)
+	if len(parts) != 3 {
+		return ""
+	}
+	decoded, err := url.PathUnescape(parts[2])
+	if err != nil {
+		return ""
+	}
+	switch charset := strings.ToLower(parts[0]); charset {
+	case "utf-8", "us-ascii", "":
+		return decoded
+	default:
+		enc, err := htmlindex.Get(charset)
+		if err != nil {
+			return ""
+		}
+		text, err := enc.NewDecoder().String(decoded)
+		if err != nil {
+			return ""
+		}
+		return text
+	}
+}
+
+// decodeFileName 解码filename参数中不规范的编码：RFC 2047编码（=?UTF-8?B?...?=）和百分号编码（部分CDN对中文文件名使用）
+func decodeFileName(fileName string) string {
+	if strings.Contains(fileName, "=?") {
+		if decoded, err := fileNameWordDecoder.DecodeHeader(fileName); err == nil {
+			fileName = decoded
+		}
+	}
+	if strings.Contains(fileName, "%") {
+		// 解码失败或结果不是有效的UTF-8时（如 "100%.xlsx"）保持原样
+		if decoded, err := url.PathUnescape(fileName); err == nil && utf8.ValidString(decoded) {
+			fileName = decoded
+		}
+	}
+	return fileName
+}
+
+// urlFileName 返回URL路径的最后一段（已按百分号编码解码），不包括查询参数和片段
+func urlFileName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	if strings.HasSuffix(u.Path, "/") {
+		return ""
+	}
+	return path.Base(u.Path)
+}
+
+// sanitizeFileName 清理文件名：去掉目录部分（防止 ../ 路径穿越）、控制字符和文件名中不允许的字符，以及两端的空白和点号
+func sanitizeFileName(fileName string) string {
+	fileName = strings.ReplaceAll(fileName, "\\", "/")
+	if idx := strings.LastIndex(fileName, "/"); idx >= 0 {
+		fileName = fileName[idx+1:]
+	}
+
+	fileName = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`<>:"|?*`, r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, fileName)
+	fileName = strings.Trim(fileName, " .")
+
+	// 文件名过长时截断扩展名之前的部分
+	if len(fileName) > maxFileNameBytes {
+		ext := path.Ext(fileName)
+		if len(ext) >= maxFileNameBytes {
+			ext = ""
+		}
+		stem := fileName[:maxFileNameBytes-len(ext)]
+		for !utf8.ValidString(stem) {
+			stem = stem[:len(stem)-1]
+		}
+		fileName = stem + ext
+	}
+	return fileName
+}
@@ -0,0 +1,58 @@
+package utils
+
+import "testing"
+
+// TestExtractFileName 从Content-Disposition和URL路径中提取并清理文件名
+func TestExtractFileName(t *testing.T) {
+	const fileURL = "https://example.com/files/url.xlsx"
+	tests := []struct {
+		name        string
+		url         string
+		disposition string
+		want        string
+	}{
+		// filename*优先于filename，与参数顺序无关
+		{"filename*在后", fileURL, `attachment; filename="a.xlsx"; filename*=UTF-8''%E6%8A%A5%E8%A1%A8.xlsx`, "报表.xlsx"},
+		{"filename*在前", fileURL, `attachment; filename*=UTF-8''%E6%8A%A5%E8%A1%A8.xlsx; filename="a.xlsx"`, "报表.xlsx"},
+		{"带语言的filename*", fileURL, `attachment; filename*=utf-8'zh-CN'%E6%8A%A5%E8%A1%A8.xlsx`, "报表.xlsx"},
+		{"GBK字符集", fileURL, `attachment; filename="fallback.xlsx"; filename*=GBK''%B1%A8%B1%ED.xlsx`, "报表.xlsx"},
+		{"只有GBK字符集的filename*", fileURL, `attachment; filename*=gbk''%B1%A8%B1%ED.xlsx`, "报表.xlsx"},
+		{"不支持的字符集", fileURL, `attachment; filename="fallback.xlsx"; filename*=x-unknown''%B1%A8.xlsx`, "fallback.xlsx"},
+
+		// 未加引号的值和其后的参数
+		{"未加引号", fileURL, `attachment; filename=report.xlsx; size=123`, "report.xlsx"},
+		{"未加引号的中文", fileURL, `attachment; filename=报表 2024.xlsx; creation-date="Wed, 12 Feb 1997 16:29:51 -0500"`, "报表 2024.xlsx"},
+		{"缺少类型", fileURL, `filename=report.xlsx;size=123`, "report.xlsx"},
+		{"引号内的分号", fileURL, `attachment; filename="a;b.xlsx"; size=1`, "a;b.xlsx"},
+
+		// 不规范的百分号编码
+		{"百分号编码的filename", fileURL, `attachment; filename="%E6%8A%A5%E8%A1%A8.xlsx"`, "报表.xlsx"},
+		{"百分号不是编码", fileURL, `attachment; filename="100%.xlsx"`, "100%.xlsx"},
+		{"无效的百分号编码", fileURL, `attachment; filename="%E6%8A%ZZ.xlsx"`, "%E6%8A%ZZ.xlsx"},
+		{"解码结果不是UTF-8", fileURL, `attachment; filename="%FF%FE.xlsx"`, "%FF%FE.xlsx"},
+		{"filename*的编码无效", fileURL, `attachment; filename="fallback.xlsx"; filename*=UTF-8''%E6%ZZ.xlsx`, "fallback.xlsx"},
+		{"RFC 2047编码", fileURL, `attachment; filename="=?UTF-8?B?5oql6KGoLnhsc3g=?="`, "报表.xlsx"},
+
+		// 路径穿越
+		{"../", fileURL, `attachment; filename="../../etc/passwd"`, "passwd"},
+		{"..\\", fileURL, `attachment; filename="..\\..\\windows\\report.xlsx"`, "report.xlsx"},
+		{"未加引号的..\\", fileURL, `attachment; filename=..\..\report.xlsx`, "report.xlsx"},
+		{"只有..", fileURL, `attachment; filename=".."`, "url.xlsx"},
+		{"文件名中不允许的字符", fileURL, `attachment; filename="a<b>:c|d?.xlsx"`, "abcd.xlsx"},
+
+		// URL路径中的文件名，不使用查询参数和片段
+		{"URL路径", "https://example.com/files/%E6%8A%A5%E8%A1%A8.xlsx", "", "报表.xlsx"},
+		{"查询参数", "https://example.com/export.xlsx?file=data.csv#sheet", "", "export.xlsx"},
+		{"路径中编码的问号", "https://example.com/files/report%3Fv=2.xlsx?name=evil.csv", "", "reportv=2.xlsx"},
+		{"只有查询参数中有文件名", "https://example.com/download?file=data.csv", "", "download"},
+		{"路径中编码的../", "https://example.com/files/..%2F..%2Fetc%2Fpasswd", "", "passwd"},
+		{"路径中编码的..\\", "https://example.com/files/..%5C..%5Creport.xlsx", "", "report.xlsx"},
+		{"目录URL", "https://example.com/files/", "", defaultFileName},
+		{"无效的URL", "://bad", "", defaultFileName},
+	}
+	for _, tt := range tests {
+		if got := extractFileName(tt.url, tt.disposition); got != tt.want {
+			t.Errorf("%s: 文件名为 %q，应为 %q", tt.name, got, tt.want)
+		}
+	}
+}
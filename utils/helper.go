@@ -1,53 +1,40 @@
 package utils
 
 import (
-	"errors"
+	"context"
 	"file-url-parser/model"
-	"io"
-	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
-// DownloadFile 从URL下载文件
-func DownloadFile(url string, maxSize int64) ([]byte, *model.FileInfo, error) {
-	// 创建HTTP请求
-	resp, err := http.Get(url)
+// DownloadFile 从URL下载文件，URL和每次重定向的目标都需要符合出站策略
+// ctx取消（如客户端断开连接）时停止下载，临时错误按下载设置重试
+func (d *Downloader) DownloadFile(ctx context.Context, rawURL string, maxSize int64) ([]byte, *model.FileInfo, error) {
+	// 检查URL是否符合出站策略
+	u, err := url.Parse(rawURL)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, &URLPolicyError{Code: URLCodeInvalid, Message: "无效的URL: " + rawURL}
 	}
-	defer resp.Body.Close()
-
-	// 检查响应状态
-	if resp.StatusCode != http.StatusOK {
-		return nil, nil, errors.New("下载失败，状态码: " + resp.Status)
+	if err := checkURL(u, d.policy); err != nil {
+		return nil, nil, err
 	}
 
-	// 获取文件信息
-	contentDisposition := resp.Header.Get("Content-Disposition")
-	contentType := resp.Header.Get("Content-Type")
-	contentLength := resp.ContentLength
-
-	// 检查文件大小
-	if contentLength > maxSize {
-		return nil, nil, errors.New("文件太大，超过最大限制")
+	// 下载文件内容
+	data, header, err := downloadWithRetry(ctx, d.client, u.String(), maxSize, d.download)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// 从URL或Content-Disposition中提取文件名
-	fileName := extractFileName(url, contentDisposition)
+	fileName := extractFileName(rawURL, header.Get("Content-Disposition"))
 	fileType := filepath.Ext(strings.ToLower(fileName))
 
-	// 读取文件内容
-	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize))
-	if err != nil {
-		return nil, nil, err
-	}
-
 	fileInfo := &model.FileInfo{
 		FileName:    fileName,
 		FileType:    fileType,
-		ContentType: contentType,
+		ContentType: header.Get("Content-Type"),
 		Size:        int64(len(data)),
 	}
 
@@ -76,35 +63,6 @@ func CleanupTempFile(filePath string) {
 	os.Remove(filePath)
 }
 
-// extractFileName 从URL或Content-Disposition中提取文件名
-func extractFileName(url, contentDisposition string) string {
-	// 尝试从Content-Disposition中提取
-	if contentDisposition != "" {
-		if strings.Contains(contentDisposition, "filename=") {
-			parts := strings.Split(contentDisposition, "filename=")
-			if len(parts) > 1 {
-				fileName := strings.Trim(parts[1], `"' `)
-				if fileName != "" {
-					return fileName
-				}
-			}
-		}
-	}
-
-	// 从URL中提取
-	urlPath := strings.Split(url, "?")[0] // 移除查询参数
-	segments := strings.Split(urlPath, "/")
-	if len(segments) > 0 {
-		fileName := segments[len(segments)-1]
-		if fileName != "" {
-			return fileName
-		}
-	}
-
-	// 默认文件名
-	return "downloaded_file"
-}
-
 // IsCommaList 检查字符串是否为逗号分隔的列表
 func IsCommaList(value string) bool {
 	return strings.Contains(value, ",")
@@ -112,10 +70,42 @@ func IsCommaList(value string) bool {
 
 // ProcessCommaList 将逗号分隔的字符串转换为字符串数组
 func ProcessCommaList(value string) []string {
-	items := strings.Split(value, ",")
-	// 去除每个项目的前后空格
-	for i, item := range items {
-		items[i] = strings.TrimSpace(item)
+	return SplitList(value, []string{","}, false)
+}
+
+// ContainsAnySeparator 检查字符串是否包含任意一个分隔符
+func ContainsAnySeparator(value string, separators []string) bool {
+	for _, separator := range separators {
+		if separator != "" && strings.Contains(value, separator) {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitList 按多个分隔符拆分字符串为数组，去除每个项目的前后空格
+// dropEmpty为true时去掉拆分后的空项目
+func SplitList(value string, separators []string, dropEmpty bool) []string {
+	items := []string{value}
+	for _, separator := range separators {
+		if separator == "" {
+			continue
+		}
+		var split []string
+		for _, item := range items {
+			split = append(split, strings.Split(item, separator)...)
+		}
+		items = split
+	}
+
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		// 去除每个项目的前后空格
+		item = strings.TrimSpace(item)
+		if dropEmpty && item == "" {
+			continue
+		}
+		result = append(result, item)
 	}
-	return items
+	return result
 }
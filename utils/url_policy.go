@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"errors"
+	"file-url-parser/model"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// 出站URL策略拒绝请求时的错误代码
+const (
+	URLCodeInvalid          = "URL_INVALID"            // URL格式无效
+	URLCodeSchemeNotAllowed = "URL_SCHEME_NOT_ALLOWED" // 协议不在允许列表中
+	URLCodeHostNotAllowed   = "URL_HOST_NOT_ALLOWED"   // 主机不在允许列表中
+	URLCodeHostDenied       = "URL_HOST_DENIED"        // 主机在禁止列表中
+	URLCodePortNotAllowed   = "URL_PORT_NOT_ALLOWED"   // 端口不在允许列表中
+	URLCodePrivateAddress   = "URL_PRIVATE_ADDRESS"    // 主机解析到内网、本机等地址
+	URLCodeTooManyRedirects = "URL_TOO_MANY_REDIRECTS" // 重定向次数过多
+)
+
+// URLPolicyError URL被出站策略拒绝的错误
+type URLPolicyError struct {
+	Code    string // 错误代码
+	Message string // 错误说明
+}
+
+// Error 返回错误说明
+func (e *URLPolicyError) Error() string {
+	return e.Message
+}
+
+// blockedPrefixes 不是公网单播地址的IP范围（IsPrivate、IsLoopback等方法之外的部分）
+// 嵌入IPv4地址的IPv6隧道和转换地址整段禁止，不按嵌入的IPv4地址判断
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // 本网络
+	netip.MustParsePrefix("100.64.0.0/10"),   // 运营商级NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF协议分配
+	netip.MustParsePrefix("192.0.2.0/24"),    // 文档示例（TEST-NET-1）
+	netip.MustParsePrefix("198.18.0.0/15"),   // 基准测试
+	netip.MustParsePrefix("198.51.100.0/24"), // 文档示例（TEST-NET-2）
+	netip.MustParsePrefix("203.0.113.0/24"),  // 文档示例（TEST-NET-3）
+	netip.MustParsePrefix("240.0.0.0/4"),     // 保留地址和广播地址
+	netip.MustParsePrefix("::/96"),           // IPv4兼容地址（已废弃），嵌入的IPv4地址可以是内网地址
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64，可映射到内网IPv4地址
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // 本地NAT64
+	netip.MustParsePrefix("2001::/32"),       // Teredo隧道，嵌入了服务器和客户端的IPv4地址
+	netip.MustParsePrefix("2001:db8::/32"),   // 文档示例
+	netip.MustParsePrefix("2002::/16"),       // 6to4隧道，嵌入的IPv4地址可以是内网地址
+}
+
+// checkURL 检查URL的协议、主机和端口是否符合出站策略（不解析域名）
+func checkURL(u *url.URL, policy model.OutboundPolicy) error {
+	scheme := strings.ToLower(u.Scheme)
+	if !containsString(policy.AllowedSchemes, scheme) {
+		return &URLPolicyError{Code: URLCodeSchemeNotAllowed, Message: "不允许的URL协议: " + u.Scheme}
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return &URLPolicyError{Code: URLCodeInvalid, Message: "URL缺少主机名"}
+	}
+	if matchHost(policy.DeniedHosts, host) {
+		return &URLPolicyError{Code: URLCodeHostDenied, Message: "禁止访问的主机: " + host}
+	}
+	if len(policy.AllowedHosts) > 0 && !matchHost(policy.AllowedHosts, host) {
+		return &URLPolicyError{Code: URLCodeHostNotAllowed, Message: "不在允许列表中的主机: " + host}
+	}
+
+	port, err := urlPort(u, scheme)
+	if err != nil {
+		return err
+	}
+	if len(policy.AllowedPorts) > 0 && !containsInt(policy.AllowedPorts, port) {
+		return &URLPolicyError{Code: URLCodePortNotAllowed, Message: "不允许的端口: " + strconv.Itoa(port)}
+	}
+
+	// 主机为IP地址时直接检查，域名在连接时按解析到的地址检查
+	if addr, err := netip.ParseAddr(host); err == nil && !policy.AllowPrivateIPs && isBlockedAddr(addr) {
+		return &URLPolicyError{Code: URLCodePrivateAddress, Message: "禁止访问内网或本机地址: " + host}
+	}
+	return nil
+}
+
+// urlPort 返回URL的端口，没有指定端口时使用协议的默认端口
+func urlPort(u *url.URL, scheme string) (int, error) {
+	portStr := u.Port()
+	if portStr == "" {
+		if scheme == "https" {
+			return 443, nil
+		}
+		return 80, nil
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		return 0, &URLPolicyError{Code: URLCodeInvalid, Message: "无效的端口: " + portStr}
+	}
+	return port, nil
+}
+
+// matchHost 检查主机是否匹配列表中的某一项
+// 列表项可以是主机名、IP地址或 *.example.com 形式的通配符（匹配所有子域名，不包括example.com本身）
+func matchHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(strings.ToLower(pattern), ".")
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+		// IPv6地址在URL中带方括号，列表中可以不带
+		if strings.Trim(pattern, "[]") == host {
+			return true
+		}
+	}
+	return false
+}
+
+// isBlockedAddr 判断是否为内网、本机、链路本地、组播等不应从服务端访问的地址
+func isBlockedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// policyDialControl 返回在DNS解析后、建立连接前检查连接地址的函数
+// 按实际连接的IP检查，避免域名解析到内网地址或在检查后改变解析结果（DNS重绑定）
+func policyDialControl(policy model.OutboundPolicy) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, _ syscall.RawConn) error {
+		if policy.AllowPrivateIPs {
+			return nil
+		}
+		addrPort, err := netip.ParseAddrPort(address)
+		if err != nil {
+			return &URLPolicyError{Code: URLCodeInvalid, Message: "无效的连接地址: " + address}
+		}
+		if isBlockedAddr(addrPort.Addr()) {
+			return &URLPolicyError{Code: URLCodePrivateAddress, Message: "禁止访问内网或本机地址: " + addrPort.Addr().String()}
+		}
+		return nil
+	}
+}
+
+// PolicyErrorCode 返回错误链中出站策略错误的代码，不是出站策略错误时返回空字符串
+func PolicyErrorCode(err error) string {
+	var policyErr *URLPolicyError
+	if errors.As(err, &policyErr) {
+		return policyErr.Code
+	}
+	return ""
+}
+
+// containsString 判断列表中是否包含指定的字符串
+func containsString(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// containsInt 判断列表中是否包含指定的整数
+func containsInt(items []int, value int) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}